@@ -0,0 +1,12 @@
+data "startrail_import_plan" "development" {
+  environment = "development"
+  exclude     = ["hello-world"]
+}
+
+output "import_blocks" {
+  value = data.startrail_import_plan.development.import_blocks
+}
+
+output "hcl" {
+  value = data.startrail_import_plan.development.hcl
+}
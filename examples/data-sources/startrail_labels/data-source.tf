@@ -0,0 +1,10 @@
+data "startrail_labels" "production" {
+  environment = "production"
+}
+
+check "team_label" {
+  assert {
+    condition     = one([for k in data.startrail_labels.production.keys : k.services if k.key == "team"]) == data.startrail_labels.production.services
+    error_message = "Every production service must have a team label."
+  }
+}
@@ -0,0 +1,21 @@
+data "startrail_service_spec" "hello_world" {
+  name        = "hello-world"
+  environment = "development"
+  description = "A service which tells hello world"
+
+  access {
+    endpoint = "https://example.com/hello"
+    auth     = true
+  }
+
+  metadata {
+    labels = {
+      team = "platform"
+    }
+  }
+}
+
+resource "local_file" "hello_world" {
+  filename = "hello-world.json"
+  content  = data.startrail_service_spec.hello_world.json
+}
@@ -0,0 +1,3 @@
+output "platform_services" {
+  value = provider::startrail::matches_selector(startrail_service.api.metadata.labels, "team=platform,tier in (web, api)")
+}
@@ -0,0 +1,6 @@
+# Services can be imported by specifying tenant/environment/name.
+terraform import startrail_service.hello_world default/development/hello-world
+
+# The tenant and environment default to the ones set in the provider configuration.
+terraform import startrail_service.hello_world development/hello-world
+terraform import startrail_service.hello_world hello-world
@@ -0,0 +1,2 @@
+# Access entries can be imported by specifying tenant/environment/service/endpoint.
+terraform import startrail_service_access.hello_world_public default/development/hello-world/https://example.com/hello
@@ -0,0 +1,6 @@
+resource "startrail_service_access" "hello_world_public" {
+  service     = startrail_service.hello_world.name
+  environment = startrail_service.hello_world.environment
+  endpoint    = "https://example.com/hello"
+  auth        = true
+}
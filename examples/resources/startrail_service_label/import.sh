@@ -0,0 +1,2 @@
+# Labels can be imported by specifying tenant/environment/service/key.
+terraform import startrail_service_label.data_classification default/production/hello-world/data-classification
@@ -0,0 +1,6 @@
+resource "startrail_service_label" "data_classification" {
+  service     = "hello-world"
+  environment = "production"
+  key         = "data-classification"
+  value       = "internal"
+}
@@ -0,0 +1,2 @@
+# Logging attachments can be imported by specifying tenant/environment/service/source.
+terraform import startrail_service_logging_attachment.hello_world_loki default/development/hello-world/loki
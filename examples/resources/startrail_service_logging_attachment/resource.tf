@@ -0,0 +1,9 @@
+resource "startrail_service_logging_attachment" "hello_world_loki" {
+  service     = "hello-world"
+  environment = "development"
+  source      = "loki"
+
+  labels = {
+    app = "hello-world"
+  }
+}
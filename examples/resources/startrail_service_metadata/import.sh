@@ -0,0 +1,2 @@
+# Service metadata can be imported by specifying tenant/environment/service.
+terraform import startrail_service_metadata.hello_world default/production/hello-world
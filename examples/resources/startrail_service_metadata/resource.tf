@@ -0,0 +1,9 @@
+resource "startrail_service_metadata" "hello_world" {
+  service     = "hello-world"
+  environment = "production"
+
+  labels = {
+    team                = "platform"
+    data-classification = "internal"
+  }
+}
@@ -0,0 +1,2 @@
+# Source attachments can be imported by specifying tenant/environment/service/source.
+terraform import 'startrail_service_source_attachment.github["hello-world"]' default/development/hello-world/github
@@ -0,0 +1,11 @@
+resource "startrail_service_source_attachment" "github" {
+  for_each = toset(["hello-world", "goodbye-world"])
+
+  service     = each.key
+  environment = "development"
+  source      = "github"
+
+  labels = {
+    repository = "srevinsaju/${each.key}"
+  }
+}
@@ -0,0 +1,231 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package acctest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// RecorderMode selects whether a Recorder talks to the API or replays a
+// cassette.
+type RecorderMode string
+
+const (
+	// RecorderModeReplay serves responses from a previously recorded cassette.
+	RecorderModeReplay RecorderMode = "replay"
+	// RecorderModeRecord forwards requests to the API and records them.
+	RecorderModeRecord RecorderMode = "record"
+)
+
+// RecorderModeFromEnv returns the mode set by STARTRAIL_VCR_MODE, replaying
+// cassettes unless told otherwise.
+func RecorderModeFromEnv() RecorderMode {
+	if RecorderMode(os.Getenv("STARTRAIL_VCR_MODE")) == RecorderModeRecord {
+		return RecorderModeRecord
+	}
+	return RecorderModeReplay
+}
+
+// sensitiveFields are replaced in recorded bodies, so that cassettes recorded
+// against a real tenant can be committed.
+var sensitiveFields = map[string]bool{
+	"key":           true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"client_secret": true,
+}
+
+// Interaction is a single request and its response in a cassette.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the sanitized part of a request kept in a cassette. The
+// host and all headers are dropped, so credentials never end up on disk.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// RecordedResponse is the sanitized part of a response kept in a cassette.
+type RecordedResponse struct {
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper which records interactions with the
+// Startrail API to a cassette, or replays them from it. Replayed requests must
+// match the recorded method, URL and body, in the recorded order.
+type Recorder struct {
+	mode      RecorderMode
+	path      string
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	next         int
+}
+
+// NewRecorder returns a Recorder for the cassette at path. In replay mode the
+// cassette must exist, in record mode requests are sent through transport.
+func NewRecorder(path string, mode RecorderMode, transport http.RoundTripper) (*Recorder, error) {
+	r := &Recorder{
+		mode:      mode,
+		path:      path,
+		transport: transport,
+	}
+	if mode == RecorderModeRecord {
+		return r, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &r.interactions); err != nil {
+		return nil, fmt.Errorf("unable to parse cassette %s: %w", path, err)
+	}
+	return r, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded := RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.RequestURI(),
+	}
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(b))
+		recorded.Body = sanitize(b)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.mode == RecorderModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	b, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+
+	r.interactions = append(r.interactions, Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode:  resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        sanitize(b),
+		},
+	})
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	if r.next >= len(r.interactions) {
+		return nil, fmt.Errorf("cassette %s has no interaction left for %s %s", r.path, recorded.Method, recorded.URL)
+	}
+	i := r.interactions[r.next]
+	if i.Request.Method != recorded.Method || i.Request.URL != recorded.URL {
+		return nil, fmt.Errorf("cassette %s expected %s %s, got %s %s", r.path, i.Request.Method, i.Request.URL, recorded.Method, recorded.URL)
+	}
+	// bodies are compared sanitized, which also normalizes the order of the
+	// fields of JSON bodies
+	if i.Request.Body != recorded.Body {
+		return nil, fmt.Errorf("cassette %s expected %s %s with body %s, got body %s", r.path, recorded.Method, recorded.URL, i.Request.Body, recorded.Body)
+	}
+	r.next++
+
+	header := http.Header{}
+	if i.Response.ContentType != "" {
+		header.Set("Content-Type", i.Response.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+		StatusCode:    i.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(i.Response.Body))),
+		ContentLength: int64(len(i.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Save writes the recorded interactions to the cassette. It does nothing in
+// replay mode.
+func (r *Recorder) Save() error {
+	if r.mode != RecorderModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, err := json.MarshalIndent(r.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(b, '\n'), 0o644)
+}
+
+// sanitize replaces sensitive fields of a JSON body. Bodies which are not JSON
+// are kept as they are.
+func sanitize(b []byte) string {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return string(b)
+	}
+	out, err := json.Marshal(redact(v))
+	if err != nil {
+		return string(b)
+	}
+	return string(out)
+}
+
+func redact(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if _, ok := e.(string); ok && sensitiveFields[k] {
+				v[k] = "REDACTED"
+			} else {
+				v[k] = redact(e)
+			}
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = redact(e)
+		}
+	}
+	return v
+}
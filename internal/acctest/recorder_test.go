@@ -0,0 +1,72 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package acctest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func recorderRequest(t *testing.T, r *Recorder, url string, body string) (string, error) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url+"/api/v1/service", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := r.RoundTrip(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b), nil
+}
+
+func TestRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(w, r.Body)
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recorder, err := NewRecorder(path, RecorderModeRecord, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recorderRequest(t, recorder, server.URL, `{"name":"hello-world","key":"secret"}`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recorderRequest(t, recorder, server.URL, `{"name":"hello"}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the same bodies replay, regardless of the order of their fields
+	replay, err := NewRecorder(path, RecorderModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := recorderRequest(t, replay, "http://startrail.invalid", `{"key":"other-secret","name":"hello-world"}`)
+	if err != nil {
+		t.Fatalf("unexpected error replaying the first request: %s", err)
+	}
+	if body != `{"key":"REDACTED","name":"hello-world"}` {
+		t.Errorf("unexpected replayed body: %s", body)
+	}
+
+	// other bodies do not
+	if _, err := recorderRequest(t, replay, "http://startrail.invalid", `{"name":"world"}`); err == nil {
+		t.Error("expected a request with another body to fail")
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package acctest provides an in-process mock of the Startrail API, so that the
+// provider can be tested without access to a real Startrail tenant.
+package acctest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+)
+
+// Server is an httptest based mock implementing the HelloAPI and ServiceAPI
+// surface of the Startrail API. Services are kept in memory.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	services map[string]bindings.Service
+	requests map[string]int
+}
+
+// NewServer starts a new mock Startrail API. The caller must Close it when
+// done.
+func NewServer() *Server {
+	s := &Server{
+		services: map[string]bindings.Service{},
+		requests: map[string]int{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func serviceKey(tenant string, environment string, name string) string {
+	return fmt.Sprintf("%s/%s/%s", tenant, environment, name)
+}
+
+// Service returns the service stored by the mock, if it exists.
+func (s *Server) Service(tenant string, environment string, name string) (bindings.Service, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	service, ok := s.services[serviceKey(tenant, environment, name)]
+	return service, ok
+}
+
+// Requests returns the number of requests the mock received for the given
+// method and path.
+func (s *Server) Requests(method string, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[method+" "+path]
+}
+
+// PutService stores a service in the mock, as if it was created outside of
+// Terraform.
+func (s *Server) PutService(service bindings.Service) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.services[serviceKey(service.Tenant, service.Environment, service.Name)] = service
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests[r.Method+" "+r.URL.Path]++
+	s.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case r.URL.Path == "/" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, bindings.HelloWorldResponse{
+			Diagnostics: []bindings.Diagnostic{},
+			Success:     true,
+		})
+	case r.URL.Path == "/.well-known/startrail/auth" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, bindings.WellKnownAuth{
+			Device: bindings.WellKnownDeviceAuth{Enabled: false},
+		})
+	case len(parts) >= 3 && parts[0] == "api" && parts[1] == "v1" && parts[2] == "service":
+		s.handleService(w, r, parts[3:])
+	default:
+		writeError(w, http.StatusNotFound, "Not Found", fmt.Sprintf("No route for %s %s", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) handleService(w http.ResponseWriter, r *http.Request, parts []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		var service bindings.Service
+		if err := json.NewDecoder(r.Body).Decode(&service); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid Service", err.Error())
+			return
+		}
+		if service.Metadata.Get() == nil {
+			service.Metadata.Set(bindings.NewMetadata(map[string]string{}))
+		}
+		service.SetUpdatedBy("acctest")
+		s.services[serviceKey(service.Tenant, service.Environment, service.Name)] = service
+		writeJSON(w, http.StatusOK, bindings.ServiceResponse{
+			Diagnostics: []bindings.Diagnostic{},
+			Response:    *bindings.NewNullableService(&service),
+			Success:     true,
+		})
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		services := []bindings.Service{}
+		keys := make([]string, 0, len(s.services))
+		for k := range s.services {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if s.services[k].Tenant == parts[0] {
+				services = append(services, s.services[k])
+			}
+		}
+		writeJSON(w, http.StatusOK, bindings.ServiceListResponse{
+			Diagnostics: []bindings.Diagnostic{},
+			Response:    services,
+			Success:     true,
+		})
+	case len(parts) == 3:
+		key := serviceKey(parts[0], parts[1], parts[2])
+		service, ok := s.services[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Service Not Found", fmt.Sprintf("Service %s does not exist", key))
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, bindings.ServiceResponse{
+				Diagnostics: []bindings.Diagnostic{},
+				Response:    *bindings.NewNullableService(&service),
+				Success:     true,
+			})
+		case http.MethodDelete:
+			delete(s.services, key)
+			writeJSON(w, http.StatusOK, bindings.StringResponse{
+				Diagnostics: []bindings.Diagnostic{},
+				Response:    *bindings.NewNullableString(bindings.PtrString("deleted")),
+				Success:     true,
+			})
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed", r.Method)
+		}
+	default:
+		writeError(w, http.StatusNotFound, "Not Found", fmt.Sprintf("No route for %s %s", r.Method, r.URL.Path))
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, summary string, detail string) {
+	writeJSON(w, status, bindings.ErrorResponse{
+		Diagnostics: []bindings.Diagnostic{
+			{
+				Severity: bindings.ERROR,
+				Summary:  summary,
+				Detail:   detail,
+			},
+		},
+		Success: false,
+	})
+}
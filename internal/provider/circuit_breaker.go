@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// circuitBreakerThreshold is the number of consecutive failed requests
+	// after which the endpoint is considered unavailable.
+	circuitBreakerThreshold = 5
+	// circuitBreakerCooldown is how long requests fail fast before a single
+	// request is let through to probe the endpoint again.
+	circuitBreakerCooldown = 30 * time.Second
+)
+
+// errEndpointUnavailable is returned for requests rejected by an open circuit
+// breaker.
+var errEndpointUnavailable = errors.New("startrail endpoint unavailable")
+
+// circuitBreaker is an http.RoundTripper which stops sending requests to an
+// endpoint that keeps failing, so that the remaining operations of an apply
+// fail quickly instead of each waiting for its own timeout.
+type circuitBreaker struct {
+	transport http.RoundTripper
+	// now returns the current time, it is replaced in tests.
+	now func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// withCircuitBreaker returns a copy of httpClient sending its requests through
+// a new circuit breaker.
+func withCircuitBreaker(httpClient *http.Client) *http.Client {
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	c := *httpClient
+	c.Transport = &circuitBreaker{transport: transport, now: time.Now}
+	return &c
+}
+
+func (b *circuitBreaker) RoundTrip(req *http.Request) (*http.Response, error) {
+	allowed, probe := b.allow()
+	if !allowed {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, errEndpointUnavailable
+	}
+
+	resp, err := b.transport.RoundTrip(req)
+	b.record(req.Context(), probe, resp, err)
+	return resp, err
+}
+
+// allow reports whether a request may be sent, and whether it is the probe of
+// an open breaker. Once the cooldown of an open breaker has passed, a single
+// probe request is allowed at a time.
+func (b *circuitBreaker) allow() (allowed bool, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < circuitBreakerThreshold {
+		return true, false
+	}
+	if b.probing || b.now().Sub(b.openedAt) < circuitBreakerCooldown {
+		return false, false
+	}
+	b.probing = true
+	return true, true
+}
+
+func (b *circuitBreaker) record(ctx context.Context, probe bool, resp *http.Response, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+	failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// canceled by Terraform or a timeout, not the endpoint's fault
+	case failed && probe:
+		// a failed probe opens the breaker for another cooldown, even when it
+		// retries an operation which was counted already
+		b.openedAt = b.now()
+	case failed && isRetryAttempt(ctx):
+		// the operation was counted when its first attempt failed
+	case failed:
+		b.failures++
+		if b.failures >= circuitBreakerThreshold {
+			b.openedAt = b.now()
+		}
+	default:
+		b.failures = 0
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fakeBreakerTransport answers every request with status, and counts them.
+type fakeBreakerTransport struct {
+	status   int
+	requests int
+}
+
+func (t *fakeBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return &http.Response{StatusCode: t.status, Body: http.NoBody, Request: req}, nil
+}
+
+// roundTripFunc is an http.RoundTripper calling itself.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newTestCircuitBreaker(status int) (*circuitBreaker, *fakeBreakerTransport, *time.Time) {
+	transport := &fakeBreakerTransport{status: status}
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	b := &circuitBreaker{transport: transport, now: func() time.Time { return now }}
+	return b, transport, &now
+}
+
+func breakerRequest(t *testing.T, b *circuitBreaker, ctx context.Context) error {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://startrail.example.com/api/v1/service/default", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := b.RoundTrip(req)
+	if err == nil {
+		_ = resp.Body.Close()
+	}
+	return err
+}
+
+func TestCircuitBreaker_opens(t *testing.T) {
+	b, transport, _ := newTestCircuitBreaker(http.StatusServiceUnavailable)
+
+	for i := 0; i < circuitBreakerThreshold; i++ {
+		if err := breakerRequest(t, b, context.Background()); err != nil {
+			t.Fatalf("request %d: unexpected error: %s", i, err)
+		}
+	}
+	if err := breakerRequest(t, b, context.Background()); !errors.Is(err, errEndpointUnavailable) {
+		t.Fatalf("expected the open breaker to reject the request, got %v", err)
+	}
+	if transport.requests != circuitBreakerThreshold {
+		t.Errorf("expected %d requests to reach the endpoint, got %d", circuitBreakerThreshold, transport.requests)
+	}
+}
+
+func TestCircuitBreaker_retryAttempts(t *testing.T) {
+	b, _, _ := newTestCircuitBreaker(http.StatusServiceUnavailable)
+
+	// a single operation retried many times counts as one failure
+	for attempt := 1; attempt <= 2*circuitBreakerThreshold; attempt++ {
+		ctx := context.WithValue(context.Background(), retryAttemptKey{}, attempt)
+		if err := breakerRequest(t, b, ctx); err != nil {
+			t.Fatalf("attempt %d: unexpected error: %s", attempt, err)
+		}
+	}
+	if b.failures != 1 {
+		t.Errorf("expected 1 failure, got %d", b.failures)
+	}
+}
+
+func TestCircuitBreaker_cancelled(t *testing.T) {
+	b, _, _ := newTestCircuitBreaker(http.StatusOK)
+	b.transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, req.Context().Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < circuitBreakerThreshold; i++ {
+		_ = breakerRequest(t, b, ctx)
+	}
+	if b.failures != 0 {
+		t.Errorf("expected cancelled requests not to count, got %d failures", b.failures)
+	}
+}
+
+func TestCircuitBreaker_halfOpen(t *testing.T) {
+	b, transport, now := newTestCircuitBreaker(http.StatusServiceUnavailable)
+
+	for i := 0; i < circuitBreakerThreshold; i++ {
+		_ = breakerRequest(t, b, context.Background())
+	}
+
+	// still open within the cooldown
+	*now = now.Add(circuitBreakerCooldown - time.Second)
+	if allowed, _ := b.allow(); allowed {
+		t.Fatal("expected the breaker to be open within the cooldown")
+	}
+
+	// a single probe once the cooldown passed
+	*now = now.Add(time.Second)
+	if allowed, probe := b.allow(); !allowed || !probe {
+		t.Fatal("expected a probe to be allowed after the cooldown")
+	}
+	if allowed, _ := b.allow(); allowed {
+		t.Fatal("expected a single probe at a time")
+	}
+
+	// a failed probe opens the breaker for another cooldown
+	b.record(context.Background(), true, &http.Response{StatusCode: http.StatusServiceUnavailable}, nil)
+	if err := breakerRequest(t, b, context.Background()); !errors.Is(err, errEndpointUnavailable) {
+		t.Fatalf("expected the breaker to open again after a failed probe, got %v", err)
+	}
+
+	// a successful probe closes it
+	*now = now.Add(circuitBreakerCooldown)
+	transport.status = http.StatusOK
+	if err := breakerRequest(t, b, context.Background()); err != nil {
+		t.Fatalf("unexpected error of the probe: %s", err)
+	}
+	if b.failures != 0 {
+		t.Errorf("expected the breaker to be closed, got %d failures", b.failures)
+	}
+	for i := 0; i < circuitBreakerThreshold; i++ {
+		if err := breakerRequest(t, b, context.Background()); err != nil {
+			t.Fatalf("request %d: unexpected error of the closed breaker: %s", i, err)
+		}
+	}
+}
+
+func TestCircuitBreaker_halfOpenRetry(t *testing.T) {
+	b, transport, now := newTestCircuitBreaker(http.StatusServiceUnavailable)
+
+	for i := 0; i < circuitBreakerThreshold; i++ {
+		_ = breakerRequest(t, b, context.Background())
+	}
+
+	// a failed probe which retries an operation opens the breaker again too
+	*now = now.Add(circuitBreakerCooldown)
+	retry := context.WithValue(context.Background(), retryAttemptKey{}, 2)
+	if err := breakerRequest(t, b, retry); err != nil {
+		t.Fatalf("unexpected error of the probe: %s", err)
+	}
+	for i := 0; i < circuitBreakerThreshold; i++ {
+		if err := breakerRequest(t, b, retry); !errors.Is(err, errEndpointUnavailable) {
+			t.Fatalf("request %d: expected the breaker to open again after a failed probe, got %v", i, err)
+		}
+	}
+	if transport.requests != circuitBreakerThreshold+1 {
+		t.Errorf("expected a single probe to reach the endpoint, got %d requests", transport.requests-circuitBreakerThreshold)
+	}
+
+	// requests sent before the breaker opened do not end the probe
+	*now = now.Add(circuitBreakerCooldown)
+	if allowed, probe := b.allow(); !allowed || !probe {
+		t.Fatal("expected a probe to be allowed after the cooldown")
+	}
+	b.record(context.Background(), false, &http.Response{StatusCode: http.StatusServiceUnavailable}, nil)
+	if allowed, _ := b.allow(); allowed {
+		t.Fatal("expected a single probe at a time")
+	}
+}
+
+func TestRetryTransient_marksRetries(t *testing.T) {
+	fakeRetryClock(t)
+
+	var retries []bool
+	_, _, err := retryTransient(context.Background(), func(ctx context.Context) (struct{}, *http.Response, error) {
+		retries = append(retries, isRetryAttempt(ctx))
+		if len(retries) == 1 {
+			return struct{}{}, &http.Response{StatusCode: http.StatusBadGateway}, nil
+		}
+		return struct{}{}, &http.Response{StatusCode: http.StatusOK}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(retries) != 2 || retries[0] || !retries[1] {
+		t.Errorf("expected only the second attempt to be marked as a retry, got %v", retries)
+	}
+}
@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// Attributes are deprecated in two steps, as described in the README. While
+// both the deprecated attribute and its replacement exist, ModifyPlan of the
+// resource calls copyDeprecatedAttribute, so that the resource only needs to
+// handle the replacement. Once the deprecated attribute is removed, the schema
+// version is increased and renamedAttributesUpgrader moves its value over in
+// existing state.
+
+// copyDeprecatedAttribute plans the configured value of the deprecated
+// attribute from as the value of its replacement to, and warns about the
+// deprecation. The replacement must be optional and computed, setting both is
+// an error.
+func copyDeprecatedAttribute[T attr.Value](ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, from path.Path, to path.Path) {
+	// nothing to plan when the resource is destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var deprecated, replacement T
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, from, &deprecated)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, to, &replacement)...)
+	if resp.Diagnostics.HasError() || deprecated.IsNull() {
+		return
+	}
+	if !replacement.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			from,
+			"Conflicting Deprecated Attribute",
+			fmt.Sprintf("%s is deprecated and replaced by %s. Remove %s from configuration.", from, to, from),
+		)
+		return
+	}
+
+	resp.Diagnostics.AddAttributeWarning(
+		from,
+		"Deprecated Attribute",
+		fmt.Sprintf("%s is deprecated and will be removed in the next major release. Use %s instead, it is set to the value of %s meanwhile.", from, to, from),
+	)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, to, deprecated)...)
+}
+
+// renamedAttributesUpgrader returns a state upgrader from a prior schema
+// version whose top-level attributes were renamed, or removed, in current.
+// renames maps prior attribute names to current ones. The values of removed
+// attributes are dropped, and null values do not overwrite the values of
+// replacements which were already set.
+func renamedAttributesUpgrader(current schema.Schema, renames map[string]string) resource.StateUpgrader {
+	return resource.StateUpgrader{
+		StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+			if req.RawState == nil || req.RawState.JSON == nil {
+				resp.Diagnostics.AddError("Unable to Upgrade State", "The prior state is not stored as JSON.")
+				return
+			}
+
+			var state map[string]json.RawMessage
+			if err := json.Unmarshal(req.RawState.JSON, &state); err != nil {
+				resp.Diagnostics.AddError("Unable to Upgrade State", "The prior state is not valid, got error: "+err.Error())
+				return
+			}
+			for from, to := range renames {
+				if v, ok := state[from]; ok && string(v) != "null" {
+					state[to] = v
+				}
+				delete(state, from)
+			}
+
+			b, err := json.Marshal(state)
+			if err != nil {
+				resp.Diagnostics.AddError("Unable to Upgrade State", "Unable to encode the upgraded state, got error: "+err.Error())
+				return
+			}
+			typ := current.Type().TerraformType(ctx)
+			value, err := tftypes.ValueFromJSONWithOpts(b, typ, tftypes.ValueFromJSONOpts{IgnoreUndefinedAttributes: true})
+			if err != nil {
+				resp.Diagnostics.AddError("Unable to Upgrade State", "The upgraded state does not match the schema, got error: "+err.Error())
+				return
+			}
+			dv, err := tfprotov6.NewDynamicValue(typ, value)
+			if err != nil {
+				resp.Diagnostics.AddError("Unable to Upgrade State", "Unable to encode the upgraded state, got error: "+err.Error())
+				return
+			}
+			resp.DynamicValue = &dv
+		},
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// deprecationTestSchema has the deprecated attribute service_name, replaced
+// by name.
+var deprecationTestSchema = schema.Schema{
+	Attributes: map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			Computed: true,
+		},
+		"name": schema.StringAttribute{
+			Optional: true,
+			Computed: true,
+		},
+		"service_name": schema.StringAttribute{
+			MarkdownDescription: "Deprecated, use `name` instead.",
+			Optional:            true,
+		},
+	},
+}
+
+func deprecationTestValue(t *testing.T, s schema.Schema, attributes map[string]tftypes.Value) tftypes.Value {
+	t.Helper()
+
+	return objectValue(s.Type().TerraformType(context.Background()).(tftypes.Object), attributes)
+}
+
+func planDeprecatedAttribute(t *testing.T, config tftypes.Value) (tftypes.Value, diag.Diagnostics) {
+	t.Helper()
+
+	planned := deprecationTestValue(t, deprecationTestSchema, map[string]tftypes.Value{
+		"id":           tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		"name":         tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		"service_name": attribute(t, config, "service_name"),
+	})
+	req := resource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: deprecationTestSchema, Raw: config},
+		Plan:   tfsdk.Plan{Schema: deprecationTestSchema, Raw: planned},
+		State:  tfsdk.State{Schema: deprecationTestSchema, Raw: tftypes.NewValue(config.Type(), nil)},
+	}
+	resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+	copyDeprecatedAttribute[types.String](context.Background(), req, resp, path.Root("service_name"), path.Root("name"))
+	return resp.Plan.Raw, resp.Diagnostics
+}
+
+func TestCopyDeprecatedAttribute(t *testing.T) {
+	plan, diags := planDeprecatedAttribute(t, deprecationTestValue(t, deprecationTestSchema, map[string]tftypes.Value{
+		"service_name": stringValue("hello-world"),
+	}))
+	if diags.HasError() || diags.WarningsCount() != 1 || diags.Warnings()[0].Summary() != "Deprecated Attribute" {
+		t.Errorf("expected a deprecation warning, got %v", diags)
+	}
+	if got := stringAttribute(t, plan, "name"); got != "hello-world" {
+		t.Errorf("expected name to be planned from service_name, got %s", got)
+	}
+
+	// the replacement alone is left as it is
+	plan, diags = planDeprecatedAttribute(t, deprecationTestValue(t, deprecationTestSchema, map[string]tftypes.Value{
+		"name": stringValue("hello-world"),
+	}))
+	if len(diags) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diags)
+	}
+	if got := attribute(t, plan, "name"); got.IsKnown() {
+		t.Errorf("expected name to be left to the resource, got %s", got)
+	}
+
+	// both are a conflict
+	_, diags = planDeprecatedAttribute(t, deprecationTestValue(t, deprecationTestSchema, map[string]tftypes.Value{
+		"name":         stringValue("hello-world"),
+		"service_name": stringValue("hello-world"),
+	}))
+	if !diags.HasError() || diags.Errors()[0].Summary() != "Conflicting Deprecated Attribute" {
+		t.Errorf("expected a conflict error, got %v", diags)
+	}
+}
+
+func TestRenamedAttributesUpgrader(t *testing.T) {
+	// the next schema version, with service_name removed
+	current := schema.Schema{
+		Version: 1,
+		Attributes: map[string]schema.Attribute{
+			"id":   deprecationTestSchema.Attributes["id"],
+			"name": deprecationTestSchema.Attributes["name"],
+		},
+	}
+	upgrader := renamedAttributesUpgrader(current, map[string]string{"service_name": "name"})
+
+	for prior, expected := range map[string]string{
+		`{"id":"default/development/hello-world","service_name":"hello-world","name":null}`:    "hello-world",
+		`{"id":"default/development/hello-world","service_name":null,"name":"hello-world"}`:    "hello-world",
+		`{"id":"default/development/hello-world","service_name":"hello-world","removed":true}`: "hello-world",
+	} {
+		resp := &resource.UpgradeStateResponse{}
+		upgrader.StateUpgrader(context.Background(), resource.UpgradeStateRequest{RawState: &tfprotov6.RawState{JSON: []byte(prior)}}, resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("%s: unexpected error: %v", prior, resp.Diagnostics)
+		}
+		typ := current.Type().TerraformType(context.Background())
+		state, err := resp.DynamicValue.Unmarshal(typ)
+		if err != nil {
+			t.Fatalf("%s: unable to decode the upgraded state: %s", prior, err)
+		}
+		if got := stringAttribute(t, state, "name"); got != expected {
+			t.Errorf("%s: expected name %s, got %s", prior, expected, got)
+		}
+		if got := stringAttribute(t, state, "id"); got != "default/development/hello-world" {
+			t.Errorf("%s: expected id to be kept, got %s", prior, got)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// discoveryCache keeps the authentication settings discovered per endpoint for
+// the lifetime of the process, so that aliased provider blocks against the
+// same endpoint discover them once.
+var discoveryCache = struct {
+	mu   sync.Mutex
+	auth map[string]bindings.WellKnownAuth
+}{
+	auth: map[string]bindings.WellKnownAuth{},
+}
+
+// discoveryCacheTTLEnv enables caching the discovery document on disk for the
+// given duration, so that separate Terraform runs skip the discovery as well.
+const discoveryCacheTTLEnv = "STARTRAIL_DISCOVERY_CACHE_TTL"
+
+// userCacheDir returns the directory of the disk cache, it is replaced in
+// tests.
+var userCacheDir = os.UserCacheDir
+
+// cachedDiscovery is the discovery document as stored on disk.
+type cachedDiscovery struct {
+	Endpoint  string                 `json:"endpoint"`
+	FetchedAt time.Time              `json:"fetched_at"`
+	Auth      bindings.WellKnownAuth `json:"auth"`
+}
+
+// discoverAuth returns the authentication settings of the endpoint the client
+// talks to, from the in-memory or disk cache when possible.
+func discoverAuth(ctx context.Context, client *bindings.APIClient, endpoint string) (*bindings.WellKnownAuth, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	discoveryCache.mu.Lock()
+	defer discoveryCache.mu.Unlock()
+
+	if auth, ok := discoveryCache.auth[endpoint]; ok {
+		return &auth, diags
+	}
+
+	ttl := discoveryCacheTTL(ctx)
+	if ttl > 0 {
+		if auth, ok := readCachedDiscovery(ctx, endpoint, ttl); ok {
+			discoveryCache.auth[endpoint] = *auth
+			return auth, diags
+		}
+	}
+
+	auth, exec, err := retryTransient(ctx, func(ctx context.Context) (*bindings.WellKnownAuth, *http.Response, error) {
+		return client.HelloAPI.WellKnownAuth(ctx).Execute()
+	})
+	if !handleStartrailResponse(&diags, "discover authentication settings", nil, exec, err) {
+		return nil, diags
+	}
+
+	discoveryCache.auth[endpoint] = *auth
+	if ttl > 0 {
+		writeCachedDiscovery(ctx, endpoint, *auth)
+	}
+	return auth, diags
+}
+
+func discoveryCacheTTL(ctx context.Context) time.Duration {
+	v := os.Getenv(discoveryCacheTTLEnv)
+	if v == "" {
+		return 0
+	}
+	ttl, err := time.ParseDuration(v)
+	if err != nil {
+		tflog.Warn(ctx, "ignoring invalid discovery cache ttl", map[string]interface{}{
+			"env":   discoveryCacheTTLEnv,
+			"error": err.Error(),
+		})
+		return 0
+	}
+	return ttl
+}
+
+func discoveryCachePath(endpoint string) (string, error) {
+	dir, err := userCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(endpoint))
+	return filepath.Join(dir, "startrail", "discovery", hex.EncodeToString(sum[:])+".json"), nil
+}
+
+// readCachedDiscovery reads the discovery document from disk. Failures are not
+// errors, the document is discovered again instead.
+func readCachedDiscovery(ctx context.Context, endpoint string, ttl time.Duration) (*bindings.WellKnownAuth, bool) {
+	path, err := discoveryCachePath(endpoint)
+	if err != nil {
+		return nil, false
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var cached cachedDiscovery
+	if err := json.Unmarshal(b, &cached); err != nil {
+		tflog.Debug(ctx, "ignoring unreadable discovery cache", map[string]interface{}{"path": path, "error": err.Error()})
+		return nil, false
+	}
+	if cached.Endpoint != endpoint || time.Since(cached.FetchedAt) > ttl {
+		return nil, false
+	}
+	return &cached.Auth, true
+}
+
+func writeCachedDiscovery(ctx context.Context, endpoint string, auth bindings.WellKnownAuth) {
+	path, err := discoveryCachePath(endpoint)
+	if err != nil {
+		return
+	}
+	b, err := json.Marshal(cachedDiscovery{
+		Endpoint:  endpoint,
+		FetchedAt: time.Now(),
+		Auth:      auth,
+	})
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0o700)
+	}
+	if err == nil {
+		err = os.WriteFile(path, b, 0o600)
+	}
+	if err != nil {
+		tflog.Debug(ctx, "unable to write discovery cache", map[string]interface{}{"path": path, "error": err.Error()})
+	}
+}
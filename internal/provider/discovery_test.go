@@ -0,0 +1,188 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+	"github.com/srevinsaju/terraform-provider-startrail/internal/acctest"
+)
+
+// useTestDiscoveryCache points the disk cache of the discovery document to a
+// temporary directory, and clears the in-memory cache.
+func useTestDiscoveryCache(t *testing.T) string {
+	dir := t.TempDir()
+	cacheDir := userCacheDir
+	userCacheDir = func() (string, error) { return dir, nil }
+
+	discoveryCache.mu.Lock()
+	auth := discoveryCache.auth
+	discoveryCache.auth = map[string]bindings.WellKnownAuth{}
+	discoveryCache.mu.Unlock()
+
+	t.Cleanup(func() {
+		userCacheDir = cacheDir
+		discoveryCache.mu.Lock()
+		discoveryCache.auth = auth
+		discoveryCache.mu.Unlock()
+	})
+	return dir
+}
+
+func writeTestDiscovery(t *testing.T, endpoint string, cached cachedDiscovery) string {
+	t.Helper()
+
+	path, err := discoveryCachePath(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(cached)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCachedDiscovery(t *testing.T) {
+	dir := useTestDiscoveryCache(t)
+	endpoint := "https://startrail.example.com"
+
+	if _, ok := readCachedDiscovery(context.Background(), endpoint, time.Hour); ok {
+		t.Fatal("expected no cached discovery before writing one")
+	}
+
+	writeCachedDiscovery(context.Background(), endpoint, bindings.WellKnownAuth{ClientId: "terraform"})
+	auth, ok := readCachedDiscovery(context.Background(), endpoint, time.Hour)
+	if !ok || auth.ClientId != "terraform" {
+		t.Fatalf("expected the cached discovery, got %v (%t)", auth, ok)
+	}
+
+	path, err := discoveryCachePath(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rel, err := filepath.Rel(dir, path); err != nil || rel != filepath.Join("startrail", "discovery", filepath.Base(path)) {
+		t.Errorf("expected the cache within %s, got %s", dir, path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected the cache to be private, got mode %s", info.Mode())
+	}
+}
+
+func TestCachedDiscovery_expired(t *testing.T) {
+	useTestDiscoveryCache(t)
+	endpoint := "https://startrail.example.com"
+
+	writeTestDiscovery(t, endpoint, cachedDiscovery{
+		Endpoint:  endpoint,
+		FetchedAt: time.Now().Add(-2 * time.Hour),
+		Auth:      bindings.WellKnownAuth{ClientId: "terraform"},
+	})
+
+	if _, ok := readCachedDiscovery(context.Background(), endpoint, time.Hour); ok {
+		t.Error("expected a discovery older than the ttl to be ignored")
+	}
+	if _, ok := readCachedDiscovery(context.Background(), endpoint, 3*time.Hour); !ok {
+		t.Error("expected a discovery within the ttl to be used")
+	}
+}
+
+func TestCachedDiscovery_corrupt(t *testing.T) {
+	useTestDiscoveryCache(t)
+	endpoint := "https://startrail.example.com"
+
+	path := writeTestDiscovery(t, endpoint, cachedDiscovery{Endpoint: endpoint, FetchedAt: time.Now()})
+	if err := os.WriteFile(path, []byte(`{"endpoint": "https://startrail.exa`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := readCachedDiscovery(context.Background(), endpoint, time.Hour); ok {
+		t.Error("expected a corrupt discovery cache to be ignored")
+	}
+
+	// it is replaced by the next discovery
+	writeCachedDiscovery(context.Background(), endpoint, bindings.WellKnownAuth{ClientId: "terraform"})
+	if auth, ok := readCachedDiscovery(context.Background(), endpoint, time.Hour); !ok || auth.ClientId != "terraform" {
+		t.Errorf("expected the rewritten discovery, got %v (%t)", auth, ok)
+	}
+}
+
+func TestCachedDiscovery_perEndpoint(t *testing.T) {
+	useTestDiscoveryCache(t)
+	endpoint := "https://startrail.example.com"
+	other := "https://startrail.example.org"
+
+	writeCachedDiscovery(context.Background(), endpoint, bindings.WellKnownAuth{ClientId: "terraform"})
+	if _, ok := readCachedDiscovery(context.Background(), other, time.Hour); ok {
+		t.Error("expected no cached discovery for another endpoint")
+	}
+
+	path, err := discoveryCachePath(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPath, err := discoveryCachePath(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path == otherPath {
+		t.Fatalf("expected endpoints to be cached in separate files, got %s", path)
+	}
+
+	// a document of another endpoint is not used, even at the path of this one
+	writeTestDiscovery(t, other, cachedDiscovery{
+		Endpoint:  endpoint,
+		FetchedAt: time.Now(),
+		Auth:      bindings.WellKnownAuth{ClientId: "terraform"},
+	})
+	if _, ok := readCachedDiscovery(context.Background(), other, time.Hour); ok {
+		t.Error("expected the discovery of another endpoint to be ignored")
+	}
+}
+
+func TestDiscoverAuth_diskCache(t *testing.T) {
+	useTestDiscoveryCache(t)
+	t.Setenv(discoveryCacheTTLEnv, "1h")
+
+	mock := acctest.NewServer()
+	t.Cleanup(mock.Close)
+	u, err := url.Parse(mock.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newClient(u, "test", "", false, nil, nil)
+
+	if _, diags := discoverAuth(context.Background(), client, mock.URL); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	// a new process only has the disk cache
+	discoveryCache.mu.Lock()
+	discoveryCache.auth = map[string]bindings.WellKnownAuth{}
+	discoveryCache.mu.Unlock()
+
+	if _, diags := discoverAuth(context.Background(), client, mock.URL); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if got := mock.Requests(http.MethodGet, "/.well-known/startrail/auth"); got != 1 {
+		t.Errorf("expected a single discovery, got %d", got)
+	}
+}
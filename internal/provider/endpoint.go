@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"fmt"
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+	"net/url"
+	"strings"
+)
+
+// apiOperations lists the operations of each API of the SDK by the key used
+// for it in api_base_paths.
+var apiOperations = map[string][]string{
+	"api_keys": {"ApiKeysAPIService.Create", "ApiKeysAPIService.Delete"},
+	"health":   {"HealthAPIService.Get"},
+	"hello":    {"HelloAPIService.Get", "HelloAPIService.WellKnownAuth"},
+	"service": {
+		"ServiceAPIService.Activity",
+		"ServiceAPIService.Create",
+		"ServiceAPIService.Delete",
+		"ServiceAPIService.Get",
+		"ServiceAPIService.History",
+		"ServiceAPIService.List",
+	},
+}
+
+// normalizeEndpoint parses the configured endpoint, defaulting the scheme to
+// https and dropping trailing slashes so that a base path is joined with the
+// request paths of the SDK without doubling slashes.
+func normalizeEndpoint(endpoint string) (*url.URL, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return &url.URL{}, nil
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing host in %q", endpoint)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("query and fragment are not supported in %q", endpoint)
+	}
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	return u, nil
+}
+
+// operationServers returns the SDK server overrides for the APIs mounted under
+// a different base path. A base path is either a path on the endpoint, or a
+// full URL.
+func operationServers(u *url.URL, basePaths map[string]string) (map[string]bindings.ServerConfigurations, error) {
+	servers := map[string]bindings.ServerConfigurations{}
+	for _, api := range sortedKeys(basePaths) {
+		operations, ok := apiOperations[api]
+		if !ok {
+			return nil, fmt.Errorf("unknown API %q, expected one of: %s", api, strings.Join(sortedKeys(apiOperations), ", "))
+		}
+
+		base := basePaths[api]
+		var server *url.URL
+		if strings.Contains(base, "://") {
+			var err error
+			if server, err = normalizeEndpoint(base); err != nil {
+				return nil, fmt.Errorf("invalid base path of API %q: %w", api, err)
+			}
+		} else {
+			s := *u
+			s.Path = strings.TrimRight("/"+strings.Trim(base, "/"), "/")
+			server = &s
+		}
+
+		for _, operation := range operations {
+			servers[operation] = bindings.ServerConfigurations{{URL: server.String()}}
+		}
+	}
+	return servers, nil
+}
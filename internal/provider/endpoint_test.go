@@ -0,0 +1,166 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+)
+
+func TestNormalizeEndpoint(t *testing.T) {
+	for _, tc := range []struct {
+		endpoint string
+		want     string
+		err      bool
+	}{
+		{endpoint: "", want: ""},
+		{endpoint: "https://startrail.example.com", want: "https://startrail.example.com"},
+		{endpoint: "https://startrail.example.com/", want: "https://startrail.example.com"},
+		{endpoint: "https://startrail.example.com///", want: "https://startrail.example.com"},
+		{endpoint: "  https://startrail.example.com  ", want: "https://startrail.example.com"},
+		{endpoint: "startrail.example.com", want: "https://startrail.example.com"},
+		{endpoint: "startrail.example.com:8443/", want: "https://startrail.example.com:8443"},
+		{endpoint: "http://localhost:8080", want: "http://localhost:8080"},
+		{endpoint: "https://gateway.example.com/startrail", want: "https://gateway.example.com/startrail"},
+		{endpoint: "https://gateway.example.com/startrail/", want: "https://gateway.example.com/startrail"},
+		{endpoint: "gateway.example.com/teams/startrail//", want: "https://gateway.example.com/teams/startrail"},
+		{endpoint: "https://", err: true},
+		{endpoint: "/startrail", err: true},
+		{endpoint: "https://startrail.example.com/?tenant=default", err: true},
+		{endpoint: "https://startrail.example.com/#api", err: true},
+		{endpoint: "https://[::1", err: true},
+	} {
+		u, err := normalizeEndpoint(tc.endpoint)
+		switch {
+		case tc.err && err == nil:
+			t.Errorf("normalizeEndpoint(%q): expected an error, got %s", tc.endpoint, u)
+		case !tc.err && err != nil:
+			t.Errorf("normalizeEndpoint(%q): unexpected error: %s", tc.endpoint, err)
+		case !tc.err && u.String() != tc.want:
+			t.Errorf("normalizeEndpoint(%q) = %s, want %s", tc.endpoint, u, tc.want)
+		}
+	}
+}
+
+func TestOperationServers(t *testing.T) {
+	for _, tc := range []struct {
+		endpoint string
+		base     string
+		want     string
+		err      bool
+	}{
+		{endpoint: "https://gateway.example.com", base: "/services", want: "https://gateway.example.com/services"},
+		{endpoint: "https://gateway.example.com", base: "services", want: "https://gateway.example.com/services"},
+		{endpoint: "https://gateway.example.com", base: "/services/", want: "https://gateway.example.com/services"},
+		{endpoint: "https://gateway.example.com", base: "/", want: "https://gateway.example.com"},
+		// a path is on the host of the endpoint, not below its base path
+		{endpoint: "https://gateway.example.com/startrail", base: "/services", want: "https://gateway.example.com/services"},
+		{endpoint: "http://localhost:8080", base: "services", want: "http://localhost:8080/services"},
+		{endpoint: "https://gateway.example.com", base: "https://services.example.com/v1/", want: "https://services.example.com/v1"},
+		{endpoint: "https://gateway.example.com", base: "http://services.example.com", want: "http://services.example.com"},
+		{endpoint: "https://gateway.example.com", base: "https://services.example.com/?v=1", err: true},
+		{endpoint: "https://gateway.example.com", base: "https://", err: true},
+	} {
+		u, err := normalizeEndpoint(tc.endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		servers, err := operationServers(u, map[string]string{"service": tc.base})
+		if tc.err {
+			if err == nil {
+				t.Errorf("base path %q: expected an error, got %v", tc.base, servers)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("base path %q: unexpected error: %s", tc.base, err)
+			continue
+		}
+
+		if len(servers) != len(apiOperations["service"]) {
+			t.Errorf("base path %q: expected servers for the operations of the service API only, got %v", tc.base, servers)
+		}
+		for _, operation := range apiOperations["service"] {
+			if got := servers[operation]; len(got) != 1 || got[0].URL != tc.want {
+				t.Errorf("base path %q: expected %s for %s, got %v", tc.base, tc.want, operation, got)
+			}
+		}
+	}
+}
+
+func TestOperationServers_unknownAPI(t *testing.T) {
+	u, err := normalizeEndpoint("https://gateway.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = operationServers(u, map[string]string{"services": "/services"})
+	if err == nil || !strings.Contains(err.Error(), "api_keys, health, hello, service") {
+		t.Errorf("expected an error listing the known APIs, got %v", err)
+	}
+}
+
+// TestApiOperations checks apiOperations against the SDK: an operation is an
+// API method with a matching Execute method, which is named by the type of its
+// service and its own name, e.g. ServiceAPIService.Get.
+func TestApiOperations(t *testing.T) {
+	var sdk []string
+	client := reflect.ValueOf(bindings.NewAPIClient(bindings.NewConfiguration())).Elem()
+	for i := 0; i < client.NumField(); i++ {
+		field := client.Type().Field(i)
+		if !field.IsExported() || field.Type.Kind() != reflect.Ptr || !strings.HasSuffix(field.Type.Elem().Name(), "APIService") {
+			continue
+		}
+		for j := 0; j < field.Type.NumMethod(); j++ {
+			method := field.Type.Method(j).Name
+			if _, ok := field.Type.MethodByName(method + "Execute"); ok {
+				sdk = append(sdk, field.Type.Elem().Name()+"."+method)
+			}
+		}
+	}
+	sort.Strings(sdk)
+
+	var listed []string
+	for _, operations := range apiOperations {
+		listed = append(listed, operations...)
+	}
+	sort.Strings(listed)
+
+	if len(sdk) == 0 || !reflect.DeepEqual(listed, sdk) {
+		t.Errorf("expected apiOperations to list the operations of the SDK %v, got %v", sdk, listed)
+	}
+}
+
+func TestOperationServers_requests(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	u, err := normalizeEndpoint(server.URL + "/startrail/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	servers, err := operationServers(u, map[string]string{"service": "/services/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newClient(u, "test", "", false, nil, servers)
+
+	_, _, _ = client.ServiceAPI.List(context.Background(), "default").Execute()
+	_, _, _ = client.HelloAPI.WellKnownAuth(context.Background()).Execute()
+
+	want := []string{"/services/api/v1/service/default", "/startrail/.well-known/startrail/auth"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("expected requests to %v, got %v", want, paths)
+	}
+}
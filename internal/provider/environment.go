@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"strings"
+)
+
+// Environment names are case-insensitive on the backend. They are sent in
+// lowercase, and the case used in configuration is kept in state, so that
+// changing only the case neither replaces resources nor shows up as a diff.
+
+// normalizeEnvironment returns the environment name as sent to the backend.
+func normalizeEnvironment(environment string) string {
+	return strings.ToLower(environment)
+}
+
+// environmentPlanModifiers replace the resource when the environment changes
+// other than in case, and warn about environments which are not lowercase.
+func environmentPlanModifiers() []planmodifier.String {
+	return []planmodifier.String{
+		stringplanmodifier.RequiresReplaceIf(
+			func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+				// an environment left to the provider configuration is
+				// planned, and replaced if need be, by the resource
+				if req.PlanValue.IsUnknown() {
+					return
+				}
+				resp.RequiresReplace = !strings.EqualFold(req.StateValue.ValueString(), req.PlanValue.ValueString())
+			},
+			"Changing the environment other than in case requires replacement.",
+			"Changing the environment other than in case requires replacement.",
+		),
+		lowercaseEnvironmentModifier{},
+	}
+}
+
+type lowercaseEnvironmentModifier struct{}
+
+func (m lowercaseEnvironmentModifier) Description(ctx context.Context) string {
+	return "Warns about environments which are not lowercase."
+}
+
+func (m lowercaseEnvironmentModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m lowercaseEnvironmentModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	environment := req.ConfigValue.ValueString()
+	if environment == normalizeEnvironment(environment) {
+		return
+	}
+	resp.Diagnostics.AddAttributeWarning(
+		req.Path,
+		"Environment Not Lowercase",
+		fmt.Sprintf("Environment names are case-insensitive, %q is sent to Startrail as %q. "+
+			"Use the lowercase name in configuration to silence this warning.", environment, normalizeEnvironment(environment)),
+	)
+}
+
+// keepEnvironmentCase keeps the environment spelled as in prior when the
+// backend returned it in a different case.
+func keepEnvironmentCase(prior types.String, environment *types.String) {
+	if !prior.IsNull() && !prior.IsUnknown() && strings.EqualFold(prior.ValueString(), environment.ValueString()) {
+		*environment = prior
+	}
+}
+
+// addEnvironmentNotConfiguredError reports that no environment is configured,
+// the API would otherwise answer with a confusing not found error.
+func addEnvironmentNotConfiguredError(diags *diag.Diagnostics, p path.Path) {
+	diags.AddAttributeError(
+		p,
+		"Environment Not Configured",
+		"The environment is empty, so Startrail cannot tell which environment the service belongs to. "+
+			"Set it to the environment the service is deployed in, for example environment = \"development\", "+
+			"on the resource or in the provider configuration. When the value comes from a variable or local, check that it is set.",
+	)
+}
+
+// environmentValidator rejects empty environments during plan.
+type environmentValidator struct{}
+
+func (v environmentValidator) Description(ctx context.Context) string {
+	return "Environment must not be empty."
+}
+
+func (v environmentValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v environmentValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() || req.ConfigValue.ValueString() != "" {
+		return
+	}
+	addEnvironmentNotConfiguredError(&resp.Diagnostics, req.Path)
+}
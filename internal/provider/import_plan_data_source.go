@@ -0,0 +1,316 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ImportPlanDataSource{}
+
+func NewImportPlanDataSource() datasource.DataSource {
+	return &ImportPlanDataSource{}
+}
+
+// ImportPlanDataSource lists the services of the tenant and renders import
+// blocks and configuration for them, to bring existing services under
+// Terraform management.
+type ImportPlanDataSource struct {
+	client *StartrailProviderClient
+}
+
+// ImportPlanDataSourceModel describes the data source data model.
+type ImportPlanDataSourceModel struct {
+	Id           types.String             `tfsdk:"id"`
+	Environment  types.String             `tfsdk:"environment"`
+	Exclude      types.Set                `tfsdk:"exclude"`
+	Services     []ImportPlanServiceModel `tfsdk:"services"`
+	ImportBlocks types.String             `tfsdk:"import_blocks"`
+	Hcl          types.String             `tfsdk:"hcl"`
+}
+
+type ImportPlanServiceModel struct {
+	Id          types.String `tfsdk:"id"`
+	Name        types.String `tfsdk:"name"`
+	Environment types.String `tfsdk:"environment"`
+	Address     types.String `tfsdk:"address"`
+}
+
+func (d *ImportPlanDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_import_plan"
+}
+
+func (d *ImportPlanDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Lists the services of the tenant which are not managed by Terraform yet, and renders " +
+			"`import` blocks and `startrail_service` configuration for them, ready to be pasted into a configuration.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Tenant, and environment when set, the plan was generated for",
+				Computed:            true,
+			},
+			"environment": schema.StringAttribute{
+				MarkdownDescription: "Only list the services of this environment. All environments are listed when unset.",
+				Optional:            true,
+			},
+			"exclude": schema.SetAttribute{
+				MarkdownDescription: "Services which are already managed and must be left out, either as `name` or `environment/name`",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"services": schema.ListNestedAttribute{
+				MarkdownDescription: "The services to import, ordered by environment and name",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Import identifier of the service",
+							Computed:            true,
+						},
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Service name",
+							Computed:            true,
+						},
+						"environment": schema.StringAttribute{
+							MarkdownDescription: "Service environment",
+							Computed:            true,
+						},
+						"address": schema.StringAttribute{
+							MarkdownDescription: "Resource address the service is imported to",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"import_blocks": schema.StringAttribute{
+				MarkdownDescription: "`import` blocks for all services",
+				Computed:            true,
+			},
+			"hcl": schema.StringAttribute{
+				MarkdownDescription: "`startrail_service` resources matching the current configuration of all services",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *ImportPlanDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*StartrailProviderClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *StartrailProviderClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *ImportPlanDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if d.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	ctx, cancel := d.client.readContext(ctx)
+	defer cancel()
+
+	var data ImportPlanDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var exclude []string
+	resp.Diagnostics.Append(data.Exclude.ElementsAs(ctx, &exclude, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	excluded := map[string]bool{}
+	for _, e := range exclude {
+		excluded[e] = true
+	}
+
+	services, diags := d.client.listServices(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	environment := data.Environment.ValueString()
+	var selected []bindings.Service
+	for _, s := range services {
+		if environment != "" && normalizeEnvironment(s.Environment) != normalizeEnvironment(environment) {
+			continue
+		}
+		if excluded[s.Name] || excluded[fmt.Sprintf("%s/%s", s.Environment, s.Name)] {
+			continue
+		}
+		selected = append(selected, s)
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		if selected[i].Environment != selected[j].Environment {
+			return selected[i].Environment < selected[j].Environment
+		}
+		return selected[i].Name < selected[j].Name
+	})
+
+	var imports, hcl strings.Builder
+	labels := map[string]bool{}
+	data.Services = []ImportPlanServiceModel{}
+	for _, s := range selected {
+		label := resourceLabel(s, environment == "", labels)
+		id := fmt.Sprintf("%s/%s/%s", d.client.Tenant, s.Environment, s.Name)
+		address := "startrail_service." + label
+
+		data.Services = append(data.Services, ImportPlanServiceModel{
+			Id:          types.StringValue(id),
+			Name:        types.StringValue(s.Name),
+			Environment: types.StringValue(s.Environment),
+			Address:     types.StringValue(address),
+		})
+
+		if imports.Len() > 0 {
+			imports.WriteString("\n")
+			hcl.WriteString("\n")
+		}
+		fmt.Fprintf(&imports, "import {\n  to = %s\n  id = %s\n}\n", address, hclString(id))
+		writeServiceHCL(&hcl, label, s, d.client.ignoreLabelPrefixes)
+	}
+
+	data.Id = types.StringValue(d.client.Tenant)
+	if environment != "" {
+		data.Id = types.StringValue(fmt.Sprintf("%s/%s", d.client.Tenant, environment))
+	}
+	data.ImportBlocks = types.StringValue(imports.String())
+	data.Hcl = types.StringValue(hcl.String())
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+var invalidLabelCharacters = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// resourceLabel returns a unique resource name for the service. The
+// environment is part of the name when services of several environments are
+// listed, as names are only unique within an environment.
+func resourceLabel(s bindings.Service, withEnvironment bool, used map[string]bool) string {
+	label := s.Name
+	if withEnvironment {
+		label = s.Environment + "_" + s.Name
+	}
+	label = invalidLabelCharacters.ReplaceAllString(label, "_")
+	if label == "" || (label[0] >= '0' && label[0] <= '9') {
+		label = "service_" + label
+	}
+
+	unique := label
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", label, i)
+	}
+	used[unique] = true
+	return unique
+}
+
+// writeServiceHCL renders a startrail_service resource matching the service.
+// Labels matching ignorePrefixes are left out, as they must not be configured.
+func writeServiceHCL(b *strings.Builder, label string, s bindings.Service, ignorePrefixes []string) {
+	fmt.Fprintf(b, "resource \"startrail_service\" %s {\n", hclString(label))
+	fmt.Fprintf(b, "  name        = %s\n", hclString(s.Name))
+	fmt.Fprintf(b, "  environment = %s\n", hclString(s.Environment))
+	if s.Description != "" {
+		fmt.Fprintf(b, "  description = %s\n", hclString(s.Description))
+	}
+	if s.GetRemarks() != "" {
+		fmt.Fprintf(b, "  remarks     = %s\n", hclString(s.GetRemarks()))
+	}
+	for _, a := range s.Access {
+		fmt.Fprintf(b, "\n  access {\n    endpoint = %s\n    auth     = %t\n    internal = %t\n  }\n", hclString(a.Endpoint), a.Auth, a.Internal)
+	}
+	for _, k := range sortedKeys(s.Logging) {
+		fmt.Fprintf(b, "\n  logging {\n    source = %s\n", hclString(k))
+		writeLabelsHCL(b, "    ", s.Logging[k].Labels)
+		b.WriteString("  }\n")
+	}
+	for _, k := range sortedKeys(s.Sources) {
+		fmt.Fprintf(b, "\n  source {\n    source = %s\n", hclString(k))
+		writeLabelsHCL(b, "    ", s.Sources[k].Labels)
+		b.WriteString("  }\n")
+	}
+	if m := s.Metadata.Get(); m != nil {
+		if labels := withoutIgnoredLabels(m.Labels, ignorePrefixes); len(labels) > 0 {
+			b.WriteString("\n  metadata {\n")
+			writeLabelsHCL(b, "    ", labels)
+			b.WriteString("  }\n")
+		}
+	}
+	b.WriteString("}\n")
+}
+
+func writeLabelsHCL(b *strings.Builder, indent string, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%slabels = {\n", indent)
+	for _, k := range sortedKeys(labels) {
+		fmt.Fprintf(b, "%s  %s = %s\n", indent, hclString(k), hclString(labels[k]))
+	}
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+// hclString quotes s as an HCL string literal. Only the escape sequences of
+// the HCL native syntax are used, which differ from those of Go: characters
+// which are not printable are written as \uNNNN or \UNNNNNNNN, and template
+// sequences are escaped as $${ and %%{.
+func hclString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i, c := range s {
+		switch {
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c == '"':
+			b.WriteString(`\"`)
+		case c == '\\':
+			b.WriteString(`\\`)
+		case (c == '$' || c == '%') && strings.HasPrefix(s[i+1:], "{"):
+			b.WriteRune(c)
+			b.WriteRune(c)
+		case !unicode.IsPrint(c) && c > 0xFFFF:
+			fmt.Fprintf(&b, `\U%08X`, c)
+		case !unicode.IsPrint(c):
+			fmt.Fprintf(&b, `\u%04X`, c)
+		default:
+			b.WriteRune(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,136 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+	"github.com/srevinsaju/terraform-provider-startrail/internal/acctest"
+)
+
+func TestImportPlanDataSource(t *testing.T) {
+	p, mock := newTestProvider(t)
+	typ := p.dataSourceType("startrail_import_plan")
+
+	for _, s := range []bindings.Service{
+		{Tenant: "default", Environment: "development", Name: "hello-world", Description: "Says ${hello}", Access: []bindings.Access{}},
+		{Tenant: "default", Environment: "development", Name: "managed", Access: []bindings.Access{}},
+		{Tenant: "default", Environment: "production", Name: "hello-world", Access: []bindings.Access{}},
+	} {
+		mock.PutService(s)
+	}
+
+	state := p.readDataSource("startrail_import_plan", objectValue(typ, map[string]tftypes.Value{
+		"environment": stringValue("development"),
+		"exclude": tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, []tftypes.Value{
+			stringValue("development/managed"),
+		}),
+	}))
+
+	var services []tftypes.Value
+	if err := attribute(t, state, "services").As(&services); err != nil || len(services) != 1 {
+		t.Fatalf("expected 1 service, got %d (%v)", len(services), err)
+	}
+	if got := stringAttribute(t, services[0], "address"); got != "startrail_service.hello_world" {
+		t.Errorf("unexpected address: %s", got)
+	}
+
+	wantImports := "import {\n  to = startrail_service.hello_world\n  id = \"default/development/hello-world\"\n}\n"
+	if got := stringAttribute(t, state, "import_blocks"); got != wantImports {
+		t.Errorf("unexpected import blocks:\n%s", got)
+	}
+	wantHCL := "resource \"startrail_service\" \"hello_world\" {\n" +
+		"  name        = \"hello-world\"\n" +
+		"  environment = \"development\"\n" +
+		"  description = \"Says $${hello}\"\n" +
+		"}\n"
+	if got := stringAttribute(t, state, "hcl"); got != wantHCL {
+		t.Errorf("unexpected hcl:\n%s", got)
+	}
+
+	// services of all environments are prefixed with their environment
+	state = p.readDataSource("startrail_import_plan", objectValue(typ, nil))
+	if err := attribute(t, state, "services").As(&services); err != nil || len(services) != 3 {
+		t.Fatalf("expected 3 services, got %d (%v)", len(services), err)
+	}
+	if got := stringAttribute(t, services[2], "address"); got != "startrail_service.production_hello_world" {
+		t.Errorf("unexpected address: %s", got)
+	}
+}
+
+func TestImportPlanDataSource_ignoredLabels(t *testing.T) {
+	mock := acctest.NewServer()
+	t.Cleanup(mock.Close)
+	p := configureTestProvider(t, mock.URL, "acctest", nil, map[string]tftypes.Value{
+		"ignore_label_prefixes": tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{
+			stringValue("startrail.io/"),
+		}),
+	})
+	typ := p.dataSourceType("startrail_import_plan")
+
+	for _, labels := range []map[string]string{
+		{"team": "platform", "startrail.io/managed-by": "startrail"},
+		{"startrail.io/managed-by": "startrail"},
+	} {
+		name := "system"
+		if len(labels) > 1 {
+			name = "hello-world"
+		}
+		mock.PutService(bindings.Service{
+			Tenant:      "default",
+			Environment: "development",
+			Name:        name,
+			Access:      []bindings.Access{},
+			Metadata:    *bindings.NewNullableMetadata(bindings.NewMetadata(labels)),
+		})
+	}
+
+	// the rendered configuration must apply, so ignored labels are left out
+	state := p.readDataSource("startrail_import_plan", objectValue(typ, map[string]tftypes.Value{
+		"environment": stringValue("development"),
+	}))
+	wantHCL := "resource \"startrail_service\" \"hello_world\" {\n" +
+		"  name        = \"hello-world\"\n" +
+		"  environment = \"development\"\n" +
+		"\n  metadata {\n" +
+		"    labels = {\n" +
+		"      \"team\" = \"platform\"\n" +
+		"    }\n" +
+		"  }\n" +
+		"}\n" +
+		"\n" +
+		"resource \"startrail_service\" \"system\" {\n" +
+		"  name        = \"system\"\n" +
+		"  environment = \"development\"\n" +
+		"}\n"
+	if got := stringAttribute(t, state, "hcl"); got != wantHCL {
+		t.Errorf("unexpected hcl:\n%s", got)
+	}
+}
+
+func TestHclString(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want string
+	}{
+		{"hello-world", `"hello-world"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`C:\path`, `"C:\\path"`},
+		{"line\nbreak\r\n\ttab", `"line\nbreak\r\n\ttab"`},
+		{"bell\a null\x00 escape\x1b", `"bell\u0007 null\u0000 escape\u001B"`},
+		{"zero\u200bwidth", `"zero\u200Bwidth"`},
+		{"tag\U000E0001", `"tag\U000E0001"`},
+		{"grüße ✓", `"grüße ✓"`},
+		{"${var.name}", `"$${var.name}"`},
+		{"%{ if true }", `"%%{ if true }"`},
+		{"$5 and 100% {", `"$5 and 100% {"`},
+		{"$${escaped}", `"$$${escaped}"`},
+	} {
+		if got := hclString(tc.in); got != tc.want {
+			t.Errorf("hclString(%q) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"strings"
+)
+
+// labelPrefixes returns the label prefixes ignored by the provider, along with
+// those of a resource level ignore_label_prefixes attribute.
+func (c *StartrailProviderClient) labelPrefixes(ctx context.Context, resourcePrefixes types.List) ([]string, diag.Diagnostics) {
+	var prefixes []string
+	diags := resourcePrefixes.ElementsAs(ctx, &prefixes, true)
+	return append(prefixes, c.ignoreLabelPrefixes...), diags
+}
+
+// ignoredLabel reports whether the label is managed outside of Terraform, like
+// the system labels the backend injects.
+func ignoredLabel(key string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutIgnoredLabels returns the labels which are not ignored.
+func withoutIgnoredLabels(labels map[string]string, prefixes []string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	m := map[string]string{}
+	for k, v := range labels {
+		if !ignoredLabel(k, prefixes) {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// withoutIgnoredLabelValues returns the labels of a label map which are not
+// ignored, null and unknown maps are returned as they are.
+func withoutIgnoredLabelValues(labels types.Map, prefixes []string) types.Map {
+	if len(prefixes) == 0 || labels.IsNull() || labels.IsUnknown() {
+		return labels
+	}
+	m := map[string]attr.Value{}
+	for k, v := range labels.Elements() {
+		if !ignoredLabel(k, prefixes) {
+			m[k] = v
+		}
+	}
+	return types.MapValueMust(types.StringType, m)
+}
@@ -0,0 +1,195 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &LabelsDataSource{}
+
+func NewLabelsDataSource() datasource.DataSource {
+	return &LabelsDataSource{}
+}
+
+// LabelsDataSource aggregates the metadata labels in use by the services of
+// the tenant.
+type LabelsDataSource struct {
+	client *StartrailProviderClient
+}
+
+// LabelsDataSourceModel describes the data source data model.
+type LabelsDataSourceModel struct {
+	Id          types.String      `tfsdk:"id"`
+	Environment types.String      `tfsdk:"environment"`
+	Services    types.Int64       `tfsdk:"services"`
+	Keys        []LabelKeyModel   `tfsdk:"keys"`
+	Labels      []LabelValueModel `tfsdk:"labels"`
+}
+
+type LabelKeyModel struct {
+	Key      types.String `tfsdk:"key"`
+	Services types.Int64  `tfsdk:"services"`
+}
+
+type LabelValueModel struct {
+	Key      types.String `tfsdk:"key"`
+	Value    types.String `tfsdk:"value"`
+	Services types.Int64  `tfsdk:"services"`
+}
+
+func (d *LabelsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_labels"
+}
+
+func (d *LabelsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Aggregates the metadata labels in use by the services of the tenant, with the number of services using them.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Tenant, and environment when set, the labels were aggregated for",
+				Computed:            true,
+			},
+			"environment": schema.StringAttribute{
+				MarkdownDescription: "Only aggregate the labels of the services of this environment. All environments are aggregated when unset.",
+				Optional:            true,
+			},
+			"services": schema.Int64Attribute{
+				MarkdownDescription: "Number of services the labels were aggregated from",
+				Computed:            true,
+			},
+			"keys": schema.ListNestedAttribute{
+				MarkdownDescription: "Label keys in use, ordered by key",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"key": schema.StringAttribute{
+							MarkdownDescription: "Label key",
+							Computed:            true,
+						},
+						"services": schema.Int64Attribute{
+							MarkdownDescription: "Number of services with a label of this key",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"labels": schema.ListNestedAttribute{
+				MarkdownDescription: "Labels in use, ordered by key and value",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"key": schema.StringAttribute{
+							MarkdownDescription: "Label key",
+							Computed:            true,
+						},
+						"value": schema.StringAttribute{
+							MarkdownDescription: "Label value",
+							Computed:            true,
+						},
+						"services": schema.Int64Attribute{
+							MarkdownDescription: "Number of services with this label",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *LabelsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*StartrailProviderClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *StartrailProviderClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *LabelsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if d.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	ctx, cancel := d.client.readContext(ctx)
+	defer cancel()
+
+	var data LabelsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	services, diags := d.client.listServices(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	environment := data.Environment.ValueString()
+	count := 0
+	keys := map[string]int64{}
+	values := map[string]map[string]int64{}
+	for i := range services {
+		s := &services[i]
+		if environment != "" && normalizeEnvironment(s.Environment) != normalizeEnvironment(environment) {
+			continue
+		}
+		count++
+		for k, v := range serviceLabels(s) {
+			keys[k]++
+			if values[k] == nil {
+				values[k] = map[string]int64{}
+			}
+			values[k][v]++
+		}
+	}
+
+	data.Keys = []LabelKeyModel{}
+	data.Labels = []LabelValueModel{}
+	for _, k := range sortedKeys(keys) {
+		data.Keys = append(data.Keys, LabelKeyModel{
+			Key:      types.StringValue(k),
+			Services: types.Int64Value(keys[k]),
+		})
+		for _, v := range sortedKeys(values[k]) {
+			data.Labels = append(data.Labels, LabelValueModel{
+				Key:      types.StringValue(k),
+				Value:    types.StringValue(v),
+				Services: types.Int64Value(values[k][v]),
+			})
+		}
+	}
+
+	data.Id = types.StringValue(d.client.Tenant)
+	if environment != "" {
+		data.Id = types.StringValue(fmt.Sprintf("%s/%s", d.client.Tenant, environment))
+	}
+	data.Services = types.Int64Value(int64(count))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
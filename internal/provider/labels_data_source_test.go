@@ -0,0 +1,53 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+)
+
+func TestLabelsDataSource(t *testing.T) {
+	p, mock := newTestProvider(t)
+	typ := p.dataSourceType("startrail_labels")
+
+	for _, s := range []struct {
+		environment string
+		name        string
+		labels      map[string]string
+	}{
+		{"development", "hello", map[string]string{"team": "platform", "tier": "web"}},
+		{"development", "world", map[string]string{"team": "platform"}},
+		{"production", "hello", map[string]string{"team": "search"}},
+	} {
+		service := bindings.Service{Tenant: "default", Environment: s.environment, Name: s.name, Access: []bindings.Access{}}
+		service.Metadata.Set(bindings.NewMetadata(s.labels))
+		mock.PutService(service)
+	}
+
+	state := p.readDataSource("startrail_labels", objectValue(typ, map[string]tftypes.Value{
+		"environment": stringValue("development"),
+	}))
+
+	var labels []tftypes.Value
+	if err := attribute(t, state, "labels").As(&labels); err != nil || len(labels) != 2 {
+		t.Fatalf("expected 2 labels, got %d (%v)", len(labels), err)
+	}
+	services := int64Attribute(t, labels[0], "services")
+	if key, value := stringAttribute(t, labels[0], "key"), stringAttribute(t, labels[0], "value"); key != "team" || value != "platform" || services != 2 {
+		t.Errorf("expected team=platform on 2 services, got %s=%s on %d", key, value, services)
+	}
+
+	// all environments
+	state = p.readDataSource("startrail_labels", objectValue(typ, nil))
+	var keys []tftypes.Value
+	if err := attribute(t, state, "keys").As(&keys); err != nil || len(keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d (%v)", len(keys), err)
+	}
+	if services := int64Attribute(t, keys[0], "services"); services != 3 {
+		t.Errorf("expected the team key on 3 services, got %d", services)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &MatchesSelectorFunction{}
+
+func NewMatchesSelectorFunction() function.Function {
+	return &MatchesSelectorFunction{}
+}
+
+// MatchesSelectorFunction reports whether labels match a label selector.
+type MatchesSelectorFunction struct{}
+
+func (f *MatchesSelectorFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "matches_selector"
+}
+
+func (f *MatchesSelectorFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Checks whether labels match a label selector",
+		MarkdownDescription: "Returns true when the labels satisfy every comma separated requirement of the selector: " +
+			"`key` and `!key` for the existence of a label, `key=value`, `key==value` and `key!=value` for equality, " +
+			"and `key in (a, b)` and `key notin (a, b)` for sets of values. An empty selector matches all labels.",
+		Parameters: []function.Parameter{
+			function.MapParameter{
+				Name:        "labels",
+				Description: "Labels to match, for example the metadata labels of a service",
+				ElementType: types.StringType,
+			},
+			function.StringParameter{
+				Name:        "selector",
+				Description: "Label selector, for example team=platform,tier in (web, api)",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *MatchesSelectorFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var labels map[string]string
+	var selector string
+
+	resp.Diagnostics.Append(req.Arguments.Get(ctx, &labels, &selector)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	requirements, err := parseSelector(selector)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid Label Selector", "Unable to parse the label selector, got error: "+err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, matchesSelector(labels, requirements))...)
+}
@@ -0,0 +1,90 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestMatchesSelector(t *testing.T) {
+	labels := map[string]string{"team": "platform", "tier": "web"}
+
+	for _, c := range []struct {
+		selector string
+		matches  bool
+	}{
+		{"", true},
+		{"team", true},
+		{"!team", false},
+		{"!owner", true},
+		{"team=platform", true},
+		{"team==platform", true},
+		{"team = search", false},
+		{"team!=search", true},
+		{"team=", false},
+		{"owner!=search", true},
+		{"tier in (web, api)", true},
+		{"tier in (api)", false},
+		{"tier notin (api)", true},
+		{"owner notin (api)", true},
+		{"owner in (api)", false},
+		{"team=platform,tier in (web,api)", true},
+		{"team=platform, tier notin (web)", false},
+	} {
+		requirements, err := parseSelector(c.selector)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", c.selector, err)
+			continue
+		}
+		if matches := matchesSelector(labels, requirements); matches != c.matches {
+			t.Errorf("%q: expected %t, got %t", c.selector, c.matches, matches)
+		}
+	}
+
+	for _, selector := range []string{"=platform", "team=a=b", "tier in ()", "tier in (web", "tier within (web)", "team,", "!"} {
+		if _, err := parseSelector(selector); err == nil {
+			t.Errorf("%q: expected an error", selector)
+		}
+	}
+}
+
+func TestMatchesSelectorFunction(t *testing.T) {
+	p, _ := newTestProvider(t)
+	server, ok := p.server.(tfprotov6.FunctionServer)
+	if !ok {
+		t.Fatal("provider server does not serve functions")
+	}
+
+	labelsType := tftypes.Map{ElementType: tftypes.String}
+	call := func(selector string) *tfprotov6.CallFunctionResponse {
+		resp, err := server.CallFunction(context.Background(), &tfprotov6.CallFunctionRequest{
+			Name: "matches_selector",
+			Arguments: []*tfprotov6.DynamicValue{
+				p.dynamicValue(labelsType, tftypes.NewValue(labelsType, map[string]tftypes.Value{
+					"team": stringValue("platform"),
+				})),
+				p.dynamicValue(tftypes.String, stringValue(selector)),
+			},
+		})
+		if err != nil {
+			t.Fatalf("unable to call function: %s", err)
+		}
+		return resp
+	}
+
+	resp := call("team in (platform, search)")
+	p.checkDiagnostics("CallFunction", resp.Diagnostics)
+	var matches bool
+	if err := p.value(tftypes.Bool, resp.Result).As(&matches); err != nil || !matches {
+		t.Errorf("expected the labels to match, got %t (%v)", matches, err)
+	}
+
+	if resp := call("team in platform"); len(resp.Diagnostics) == 0 {
+		t.Error("expected an error for an invalid selector")
+	}
+}
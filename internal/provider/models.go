@@ -86,4 +86,6 @@ type ServiceModel struct {
 	Name        types.String                  `tfsdk:"name"`
 	Remarks     types.String                  `tfsdk:"remarks"`
 	Sources     []ServiceResourceM0delSource  `tfsdk:"source"`
+
+	IgnoreLabelPrefixes types.List `tfsdk:"ignore_label_prefixes"`
 }
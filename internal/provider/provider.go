@@ -7,20 +7,25 @@ import (
 	"context"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	bindings "github.com/srevinsaju/startrail-go-sdk"
-	"github.com/zalando/go-keyring"
 	"golang.org/x/oauth2"
 	"net/http"
 	"net/url"
 	"os"
+	"time"
 )
 
 // Ensure StartrailProvider satisfies various provider interfaces.
 var _ provider.Provider = &StartrailProvider{}
+var _ provider.ProviderWithFunctions = &StartrailProvider{}
 
 // StartrailProvider defines the provider implementation.
 type StartrailProvider struct {
@@ -28,21 +33,43 @@ type StartrailProvider struct {
 	// provider is built and ran locally, and "test" when running acceptance
 	// testing.
 	version string
+
+	// httpClient is used for all API requests, tests replace it to record or
+	// replay interactions. sharedHTTPClient is used when nil.
+	httpClient *http.Client
 }
 
 // StartrailProviderModel describes the provider data model.
 type StartrailProviderModel struct {
-	Endpoint    types.String `tfsdk:"endpoint"`
-	ApiKey      types.String `tfsdk:"api_key"`
-	Debug       types.Bool   `tfsdk:"debug"`
-	Environment types.String `tfsdk:"environment"`
-	Tenant      types.String `tfsdk:"tenant"`
+	Endpoint     types.String `tfsdk:"endpoint"`
+	ApiKey       types.String `tfsdk:"api_key"`
+	Token        types.String `tfsdk:"token"`
+	Debug        types.Bool   `tfsdk:"debug"`
+	Environment  types.String `tfsdk:"environment"`
+	Tenant       types.String `tfsdk:"tenant"`
+	ReadTimeout  types.String `tfsdk:"read_timeout"`
+	WriteTimeout types.String `tfsdk:"write_timeout"`
+	ApiBasePaths types.Map    `tfsdk:"api_base_paths"`
+
+	IgnoreLabelPrefixes types.List `tfsdk:"ignore_label_prefixes"`
 }
 
 type StartrailProviderClient struct {
 	Client      *bindings.APIClient
 	Tenant      string
 	Environment string
+
+	// readTimeout and writeTimeout bound a single refresh, respectively a
+	// single create, update or delete. Zero means no timeout.
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+
+	// ignoreLabelPrefixes are the prefixes of labels managed outside of
+	// Terraform, which are neither read into state nor written back.
+	ignoreLabelPrefixes []string
+
+	serviceLocks serviceLocks
+	serviceCache serviceCache
 }
 
 func (p *StartrailProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -54,14 +81,26 @@ func (p *StartrailProvider) Schema(ctx context.Context, req provider.SchemaReque
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"endpoint": schema.StringAttribute{
-				MarkdownDescription: "The upstream endpoint to use for API requests.",
-				Optional:            true,
+				MarkdownDescription: "The upstream endpoint to use for API requests. " +
+					"Defaults to `https://` when no scheme is given, and may include a base path.",
+				Optional: true,
+			},
+			"api_base_paths": schema.MapAttribute{
+				MarkdownDescription: "Base paths of APIs mounted under a different prefix than the endpoint, " +
+					"keyed by API: `api_keys`, `health`, `hello` or `service`. A base path is a path on the endpoint or a full URL.",
+				Optional:    true,
+				ElementType: types.StringType,
 			},
 			"api_key": schema.StringAttribute{
 				MarkdownDescription: "The API key to use for API requests.",
 				Optional:            true,
 				Sensitive:           true,
 			},
+			"token": schema.StringAttribute{
+				MarkdownDescription: "The bearer token to use for API requests, takes precedence over `api_key`.",
+				Optional:            true,
+				Sensitive:           true,
+			},
 			"tenant": schema.StringAttribute{
 				MarkdownDescription: "The tenant to use for API requests.",
 				Optional:            true,
@@ -74,11 +113,25 @@ func (p *StartrailProvider) Schema(ctx context.Context, req provider.SchemaReque
 				MarkdownDescription: "Enable debug mode.",
 				Optional:            true,
 			},
+			"ignore_label_prefixes": schema.ListAttribute{
+				MarkdownDescription: "Prefixes of service labels managed outside of Terraform, for example the system labels " +
+					"`startrail.io/` injected by Startrail. Matching labels are not read into state, not removed from services, and cannot be set.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"read_timeout": schema.StringAttribute{
+				MarkdownDescription: "Maximum duration of reading a resource or data source, for example `30s`. Unset means no timeout.",
+				Optional:            true,
+			},
+			"write_timeout": schema.StringAttribute{
+				MarkdownDescription: "Maximum duration of creating, updating or deleting a resource, for example `10m`. Unset means no timeout.",
+				Optional:            true,
+			},
 		},
 	}
 }
 
-func newClient(u *url.URL, version string, authorization string, debug bool) *bindings.APIClient {
+func newClient(u *url.URL, version string, authorization string, debug bool, httpClient *http.Client, servers map[string]bindings.ServerConfigurations) *bindings.APIClient {
 	client := bindings.NewAPIClient(&bindings.Configuration{
 		Host:   "",
 		Scheme: "",
@@ -92,12 +145,49 @@ func newClient(u *url.URL, version string, authorization string, debug bool) *bi
 				URL: u.String(),
 			},
 		},
-		OperationServers: nil,
-		HTTPClient:       http.DefaultClient,
+		OperationServers: servers,
+		HTTPClient:       httpClient,
 	})
 	return client
 }
 
+// deviceFlowToken exchanges the refresh token stored in the keyring by a
+// previous device flow login for an access token.
+func deviceFlowToken(ctx context.Context, u *url.URL, version string, debug bool, httpClient *http.Client, servers map[string]bindings.ServerConfigurations) (string, diag.Diagnostics) {
+	client := newClient(u, version, "", debug, httpClient, servers)
+	auth, diags := discoverAuth(ctx, client, u.String())
+	if diags.HasError() {
+		return "", diags
+	}
+	if !auth.Device.Enabled {
+		diags.AddError("Client Error", "Device flow is not enabled for the tenant. Please pass an 'api_key' instead")
+		return "", diags
+	}
+
+	config := oauth2.Config{
+		ClientID: auth.Device.GetClientId(),
+		Endpoint: oauth2.Endpoint{
+			AuthURL:       auth.Device.GetAuthorizationUrl(),
+			DeviceAuthURL: auth.Device.GetDeviceCodeUrl(),
+			TokenURL:      auth.Device.GetTokenUrl(),
+			AuthStyle:     0,
+		},
+		RedirectURL: "",
+		Scopes:      auth.Device.GetScopes(),
+	}
+	tokenSource, err := sharedTokenSource(u.String(), config)
+	if err != nil {
+		diags.AddError("Client Error", "Unable to get refresh token from keyring, got error: "+err.Error())
+		return "", diags
+	}
+	t, err := tokenSource.Token()
+	if err != nil {
+		diags.AddError("Client Error", "Unable to get token from token source, got error: "+err.Error())
+		return "", diags
+	}
+	return fmt.Sprintf("Bearer %s", t.AccessToken), diags
+}
+
 func (p *StartrailProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
 	var data StartrailProviderModel
 
@@ -107,68 +197,64 @@ func (p *StartrailProvider) Configure(ctx context.Context, req provider.Configur
 		return
 	}
 
-	// Configuration values are now available.
-	// if data.Endpoint.IsNull() { /* ... */ }
+	// Credentials may be supplied by ephemeral resources or by attributes of
+	// resources which are not created yet. Leave the provider unconfigured
+	// until every value is known, resources keep their prior state meanwhile.
+	if data.Endpoint.IsUnknown() || data.ApiKey.IsUnknown() || data.Token.IsUnknown() ||
+		data.Tenant.IsUnknown() || data.Environment.IsUnknown() || data.ApiBasePaths.IsUnknown() ||
+		data.IgnoreLabelPrefixes.IsUnknown() || data.ReadTimeout.IsUnknown() || data.WriteTimeout.IsUnknown() {
+		tflog.Debug(ctx, "provider configuration is not known yet, skipping client configuration")
+		return
+	}
 
 	// Example client configuration for data sources and resources
-	u, err := url.Parse(data.Endpoint.ValueString())
+	u, err := normalizeEndpoint(data.Endpoint.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError("Invalid endpoint", "The endpoint is not a valid URL, got error: "+err.Error())
 		return
 	}
-	var token string
+	var basePaths map[string]string
+	resp.Diagnostics.Append(data.ApiBasePaths.ElementsAs(ctx, &basePaths, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	servers, err := operationServers(u, basePaths)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("api_base_paths"), "Invalid API Base Path", err.Error())
+		return
+	}
+	httpClient := p.httpClient
+	if httpClient == nil {
+		httpClient = sharedHTTPClient
+	}
+	httpClient = withCircuitBreaker(httpClient)
 
-	if os.Getenv("STARTRAIL_TOKEN") == "" && os.Getenv("STARTRAIL_API_KEY") == "" || data.ApiKey.IsNull() {
-		client := newClient(u, p.version, "", data.Debug.ValueBool())
-		auth, exec, err := client.HelloAPI.WellKnownAuth(ctx).Execute()
-		if err != nil {
-			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to authenticate, got error: %s", err))
-			return
-		}
-		if exec.StatusCode != 200 {
-			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to authenticate, got status code: %d", exec.StatusCode))
-			return
-		}
-		if !auth.Device.Enabled {
-			resp.Diagnostics.AddError("Client Error", "Device flow is not enabled for the tenant. Please pass an 'api_key' instead")
-			return
-		}
+	var ignoreLabelPrefixes []string
+	resp.Diagnostics.Append(data.IgnoreLabelPrefixes.ElementsAs(ctx, &ignoreLabelPrefixes, false)...)
+	readTimeout := parseTimeout(&resp.Diagnostics, "read_timeout", data.ReadTimeout)
+	writeTimeout := parseTimeout(&resp.Diagnostics, "write_timeout", data.WriteTimeout)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-		config := oauth2.Config{
-			ClientID: auth.Device.GetClientId(),
-			Endpoint: oauth2.Endpoint{
-				AuthURL:       auth.Device.GetAuthorizationUrl(),
-				DeviceAuthURL: auth.Device.GetDeviceCodeUrl(),
-				TokenURL:      auth.Device.GetTokenUrl(),
-				AuthStyle:     0,
-			},
-			RedirectURL: "",
-			Scopes:      auth.Device.GetScopes(),
-		}
-		refreshToken, err := keyring.Get("startrail", "refresh_token")
-		if err != nil {
-			resp.Diagnostics.AddError("Client Error", "Unable to get refresh token from keyring, got error: "+err.Error())
-			return
-		}
-		tokenSource := config.TokenSource(ctx, &oauth2.Token{
-			RefreshToken: refreshToken,
-		})
-		t, err := tokenSource.Token()
-		// write the new refresh token to the keyring
-		if err != nil {
-			resp.Diagnostics.AddError("Client Error", "Unable to get token from token source, got error: "+err.Error())
-			return
-		}
-		if t.RefreshToken != "" {
-			_ = keyring.Set("startrail", "refresh_token", t.RefreshToken)
-		}
-		token = fmt.Sprintf("Bearer %s", t.AccessToken)
-	} else if os.Getenv("STARTRAIL_TOKEN") != "" {
+	var token string
+
+	switch {
+	case data.Token.ValueString() != "":
+		token = fmt.Sprintf("Bearer %s", data.Token.ValueString())
+	case data.ApiKey.ValueString() != "":
+		token = fmt.Sprintf("apiKey %s", data.ApiKey.ValueString())
+	case os.Getenv("STARTRAIL_TOKEN") != "":
 		token = fmt.Sprintf("Bearer %s", os.Getenv("STARTRAIL_TOKEN"))
-	} else if os.Getenv("STARTRAIL_API_KEY") != "" {
+	case os.Getenv("STARTRAIL_API_KEY") != "":
 		token = fmt.Sprintf("apiKey %s", os.Getenv("STARTRAIL_API_KEY"))
-	} else {
-		token = fmt.Sprintf("apiKey %s", data.ApiKey.ValueString())
+	default:
+		var diags diag.Diagnostics
+		token, diags = deviceFlowToken(ctx, u, p.version, data.Debug.ValueBool(), httpClient, servers)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
 	}
 
 	tenant := data.Tenant.ValueString()
@@ -176,26 +262,84 @@ func (p *StartrailProvider) Configure(ctx context.Context, req provider.Configur
 		tenant = "default"
 	}
 
-	client := newClient(u, p.version, token, data.Debug.ValueBool())
+	client := newClient(u, p.version, token, data.Debug.ValueBool(), httpClient, servers)
 	c := &StartrailProviderClient{
 		Client:      client,
 		Tenant:      tenant,
 		Environment: data.Environment.ValueString(),
+
+		readTimeout:  readTimeout,
+		writeTimeout: writeTimeout,
+
+		ignoreLabelPrefixes: ignoreLabelPrefixes,
 	}
 
 	resp.DataSourceData = c
 	resp.ResourceData = c
 }
 
+// parseTimeout parses the duration of a timeout attribute, an unset attribute
+// is no timeout.
+func parseTimeout(diags *diag.Diagnostics, attribute string, value types.String) time.Duration {
+	if value.ValueString() == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(value.ValueString())
+	if err == nil && d <= 0 {
+		err = fmt.Errorf("must be positive")
+	}
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root(attribute),
+			"Invalid Timeout",
+			fmt.Sprintf("The %s %q is not a valid duration: %s", attribute, value.ValueString(), err),
+		)
+		return 0
+	}
+	return d
+}
+
+// readContext returns the context to read resources and data sources with.
+func (c *StartrailProviderClient) readContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, c.readTimeout)
+}
+
+// writeContext returns the context to create, update and delete resources
+// with.
+func (c *StartrailProviderClient) writeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, c.writeTimeout)
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
 func (p *StartrailProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewServiceResource,
+		NewServiceAccessResource,
+		NewServiceLoggingAttachmentResource,
+		NewServiceSourceAttachmentResource,
+		NewServiceLabelResource,
+		NewServiceMetadataResource,
 	}
 }
 
 func (p *StartrailProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewServiceDataSource,
+		NewImportPlanDataSource,
+		NewServiceSpecDataSource,
+		NewLabelsDataSource,
+	}
+}
+
+func (p *StartrailProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewMatchesSelectorFunction,
 	}
 }
 
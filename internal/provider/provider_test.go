@@ -0,0 +1,697 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/srevinsaju/terraform-provider-startrail/internal/acctest"
+)
+
+// testProvider drives the provider through the plugin protocol the same way
+// Terraform does, so that resources can be exercised end to end against the
+// mock Startrail API without a Terraform binary.
+type testProvider struct {
+	t       *testing.T
+	server  tfprotov6.ProviderServer
+	schemas *tfprotov6.GetProviderSchemaResponse
+}
+
+// newTestProvider starts a mock Startrail API and returns a provider
+// configured against it.
+func newTestProvider(t *testing.T) (*testProvider, *acctest.Server) {
+	t.Helper()
+
+	mock := acctest.NewServer()
+	t.Cleanup(mock.Close)
+
+	return configureTestProvider(t, mock.URL, "acctest", nil, nil), mock
+}
+
+// newRecordedTestProvider returns a provider replaying the cassette of the
+// test from testdata/cassettes. With STARTRAIL_VCR_MODE=record the cassette is
+// recorded instead, against STARTRAIL_ENDPOINT with the credentials from the
+// environment, or against the mock API when no endpoint is set.
+func newRecordedTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+
+	path := filepath.Join("testdata", "cassettes", t.Name()+".json")
+	mode := acctest.RecorderModeFromEnv()
+	endpoint := "http://startrail.invalid"
+	apiKey := "acctest"
+	if mode == acctest.RecorderModeRecord {
+		if endpoint = os.Getenv("STARTRAIL_ENDPOINT"); endpoint != "" {
+			apiKey = ""
+		} else {
+			mock := acctest.NewServer()
+			t.Cleanup(mock.Close)
+			endpoint = mock.URL
+		}
+	} else if _, err := os.Stat(path); os.IsNotExist(err) {
+		t.Skipf("no cassette recorded at %s", path)
+	}
+
+	recorder, err := acctest.NewRecorder(path, mode, http.DefaultTransport)
+	if err != nil {
+		t.Fatalf("unable to create recorder: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := recorder.Save(); err != nil {
+			t.Errorf("unable to save cassette: %s", err)
+		}
+	})
+
+	return configureTestProvider(t, endpoint, apiKey, &http.Client{Transport: recorder}, nil)
+}
+
+// configureTestProvider returns a provider configured against endpoint, along
+// with the given attributes of the provider configuration. An empty apiKey
+// leaves the credentials to the environment.
+func configureTestProvider(t *testing.T, endpoint string, apiKey string, httpClient *http.Client, config map[string]tftypes.Value) *testProvider {
+	t.Helper()
+
+	server, err := providerserver.NewProtocol6WithError(&StartrailProvider{
+		version:    "test",
+		httpClient: httpClient,
+	})()
+	if err != nil {
+		t.Fatalf("unable to create provider server: %s", err)
+	}
+	p := &testProvider{t: t, server: server}
+
+	p.schemas, err = server.GetProviderSchema(context.Background(), &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("unable to get provider schema: %s", err)
+	}
+	p.checkDiagnostics("GetProviderSchema", p.schemas.Diagnostics)
+
+	configType := p.schemas.Provider.ValueType().(tftypes.Object)
+	attributes := map[string]tftypes.Value{
+		"endpoint": stringValue(endpoint),
+		"tenant":   stringValue("default"),
+	}
+	if apiKey != "" {
+		attributes["api_key"] = stringValue(apiKey)
+	}
+	for k, v := range config {
+		attributes[k] = v
+	}
+	resp, err := server.ConfigureProvider(context.Background(), &tfprotov6.ConfigureProviderRequest{
+		TerraformVersion: "1.7.0",
+		Config:           p.dynamicValue(configType, objectValue(configType, attributes)),
+	})
+	if err != nil {
+		t.Fatalf("unable to configure provider: %s", err)
+	}
+	p.checkDiagnostics("ConfigureProvider", resp.Diagnostics)
+
+	return p
+}
+
+func (p *testProvider) checkDiagnostics(operation string, diags []*tfprotov6.Diagnostic) {
+	p.t.Helper()
+
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			p.t.Fatalf("%s returned error: %s: %s", operation, d.Summary, d.Detail)
+		}
+	}
+}
+
+func (p *testProvider) dynamicValue(typ tftypes.Type, value tftypes.Value) *tfprotov6.DynamicValue {
+	p.t.Helper()
+
+	dv, err := tfprotov6.NewDynamicValue(typ, value)
+	if err != nil {
+		p.t.Fatalf("unable to encode value: %s", err)
+	}
+	return &dv
+}
+
+func (p *testProvider) value(typ tftypes.Type, dv *tfprotov6.DynamicValue) tftypes.Value {
+	p.t.Helper()
+
+	v, err := dv.Unmarshal(typ)
+	if err != nil {
+		p.t.Fatalf("unable to decode value: %s", err)
+	}
+	return v
+}
+
+// resourceType returns the object type of the given resource.
+func (p *testProvider) resourceType(typeName string) tftypes.Object {
+	p.t.Helper()
+
+	s, ok := p.schemas.ResourceSchemas[typeName]
+	if !ok {
+		p.t.Fatalf("resource %s is not registered", typeName)
+	}
+	return s.ValueType().(tftypes.Object)
+}
+
+// apply plans and applies config against the prior state and returns the new
+// state. A null config destroys the resource.
+func (p *testProvider) apply(typeName string, prior tftypes.Value, config tftypes.Value) tftypes.Value {
+	p.t.Helper()
+
+	typ := p.resourceType(typeName)
+	proposed := proposedNewState(p.schemas.ResourceSchemas[typeName].Block, prior, config)
+
+	plan, err := p.server.PlanResourceChange(context.Background(), &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       p.dynamicValue(typ, prior),
+		ProposedNewState: p.dynamicValue(typ, proposed),
+		Config:           p.dynamicValue(typ, config),
+	})
+	if err != nil {
+		p.t.Fatalf("unable to plan %s: %s", typeName, err)
+	}
+	p.checkDiagnostics("PlanResourceChange", plan.Diagnostics)
+
+	resp, err := p.server.ApplyResourceChange(context.Background(), &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:     typeName,
+		PriorState:   p.dynamicValue(typ, prior),
+		PlannedState: plan.PlannedState,
+		Config:       p.dynamicValue(typ, config),
+	})
+	if err != nil {
+		p.t.Fatalf("unable to apply %s: %s", typeName, err)
+	}
+	p.checkDiagnostics("ApplyResourceChange", resp.Diagnostics)
+
+	return p.value(typ, resp.NewState)
+}
+
+// plan plans config against the prior state and returns the planned state.
+func (p *testProvider) plan(typeName string, prior tftypes.Value, config tftypes.Value) tftypes.Value {
+	p.t.Helper()
+
+	typ := p.resourceType(typeName)
+	proposed := proposedNewState(p.schemas.ResourceSchemas[typeName].Block, prior, config)
+
+	resp, err := p.server.PlanResourceChange(context.Background(), &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       p.dynamicValue(typ, prior),
+		ProposedNewState: p.dynamicValue(typ, proposed),
+		Config:           p.dynamicValue(typ, config),
+	})
+	if err != nil {
+		p.t.Fatalf("unable to plan %s: %s", typeName, err)
+	}
+	p.checkDiagnostics("PlanResourceChange", resp.Diagnostics)
+
+	return p.value(typ, resp.PlannedState)
+}
+
+// applyError applies config to the prior state and returns the error applying
+// fails with. Planning must succeed.
+func (p *testProvider) applyError(typeName string, prior tftypes.Value, config tftypes.Value) *tfprotov6.Diagnostic {
+	p.t.Helper()
+
+	typ := p.resourceType(typeName)
+	proposed := proposedNewState(p.schemas.ResourceSchemas[typeName].Block, prior, config)
+
+	plan, err := p.server.PlanResourceChange(context.Background(), &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       p.dynamicValue(typ, prior),
+		ProposedNewState: p.dynamicValue(typ, proposed),
+		Config:           p.dynamicValue(typ, config),
+	})
+	if err != nil {
+		p.t.Fatalf("unable to plan %s: %s", typeName, err)
+	}
+	p.checkDiagnostics("PlanResourceChange", plan.Diagnostics)
+
+	resp, err := p.server.ApplyResourceChange(context.Background(), &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:     typeName,
+		PriorState:   p.dynamicValue(typ, prior),
+		PlannedState: plan.PlannedState,
+		Config:       p.dynamicValue(typ, config),
+	})
+	if err != nil {
+		p.t.Fatalf("unable to apply %s: %s", typeName, err)
+	}
+	for _, d := range resp.Diagnostics {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			return d
+		}
+	}
+	p.t.Fatalf("expected the apply of %s to fail", typeName)
+	return nil
+}
+
+// planError plans config against the prior state and returns the summary of
+// the error planning fails with.
+func (p *testProvider) planError(typeName string, prior tftypes.Value, config tftypes.Value) string {
+	p.t.Helper()
+
+	typ := p.resourceType(typeName)
+	proposed := proposedNewState(p.schemas.ResourceSchemas[typeName].Block, prior, config)
+
+	plan, err := p.server.PlanResourceChange(context.Background(), &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       p.dynamicValue(typ, prior),
+		ProposedNewState: p.dynamicValue(typ, proposed),
+		Config:           p.dynamicValue(typ, config),
+	})
+	if err != nil {
+		p.t.Fatalf("unable to plan %s: %s", typeName, err)
+	}
+	for _, d := range plan.Diagnostics {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			return d.Summary
+		}
+	}
+	p.t.Fatalf("expected the plan of %s to fail", typeName)
+	return ""
+}
+
+// read refreshes the given state.
+func (p *testProvider) read(typeName string, state tftypes.Value) tftypes.Value {
+	p.t.Helper()
+
+	state, _ = p.readWarnings(typeName, state)
+	return state
+}
+
+// readWarnings refreshes the given state and returns it along with the
+// summaries of the warnings of the refresh.
+func (p *testProvider) readWarnings(typeName string, state tftypes.Value) (tftypes.Value, []string) {
+	p.t.Helper()
+
+	typ := p.resourceType(typeName)
+	resp, err := p.server.ReadResource(context.Background(), &tfprotov6.ReadResourceRequest{
+		TypeName:     typeName,
+		CurrentState: p.dynamicValue(typ, state),
+	})
+	if err != nil {
+		p.t.Fatalf("unable to read %s: %s", typeName, err)
+	}
+	p.checkDiagnostics("ReadResource", resp.Diagnostics)
+
+	var warnings []string
+	for _, d := range resp.Diagnostics {
+		if d.Severity == tfprotov6.DiagnosticSeverityWarning {
+			warnings = append(warnings, d.Summary)
+		}
+	}
+	return p.value(typ, resp.NewState), warnings
+}
+
+// readDataSource reads the data source with the given config and returns its
+// state.
+func (p *testProvider) readDataSource(typeName string, config tftypes.Value) tftypes.Value {
+	p.t.Helper()
+
+	typ := p.dataSourceType(typeName)
+	resp, err := p.server.ReadDataSource(context.Background(), &tfprotov6.ReadDataSourceRequest{
+		TypeName: typeName,
+		Config:   p.dynamicValue(typ, config),
+	})
+	if err != nil {
+		p.t.Fatalf("unable to read %s: %s", typeName, err)
+	}
+	p.checkDiagnostics("ReadDataSource", resp.Diagnostics)
+
+	return p.value(typ, resp.State)
+}
+
+// readDataSourceError validates and reads the data source, and returns the
+// summary of the error either fails with.
+func (p *testProvider) readDataSourceError(typeName string, config tftypes.Value) string {
+	p.t.Helper()
+
+	typ := p.dataSourceType(typeName)
+	validate, err := p.server.ValidateDataResourceConfig(context.Background(), &tfprotov6.ValidateDataResourceConfigRequest{
+		TypeName: typeName,
+		Config:   p.dynamicValue(typ, config),
+	})
+	if err != nil {
+		p.t.Fatalf("unable to validate %s: %s", typeName, err)
+	}
+	diags := validate.Diagnostics
+	if len(diags) == 0 {
+		resp, err := p.server.ReadDataSource(context.Background(), &tfprotov6.ReadDataSourceRequest{
+			TypeName: typeName,
+			Config:   p.dynamicValue(typ, config),
+		})
+		if err != nil {
+			p.t.Fatalf("unable to read %s: %s", typeName, err)
+		}
+		diags = resp.Diagnostics
+	}
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			return d.Summary
+		}
+	}
+	p.t.Fatalf("expected the read of %s to fail", typeName)
+	return ""
+}
+
+// dataSourceType returns the object type of the given data source.
+func (p *testProvider) dataSourceType(typeName string) tftypes.Object {
+	p.t.Helper()
+
+	s, ok := p.schemas.DataSourceSchemas[typeName]
+	if !ok {
+		p.t.Fatalf("data source %s is not registered", typeName)
+	}
+	return s.ValueType().(tftypes.Object)
+}
+
+// importState imports the resource with the given identifier and refreshes
+// it, like terraform import does.
+func (p *testProvider) importState(typeName string, id string) tftypes.Value {
+	p.t.Helper()
+
+	typ := p.resourceType(typeName)
+	resp, err := p.server.ImportResourceState(context.Background(), &tfprotov6.ImportResourceStateRequest{
+		TypeName: typeName,
+		ID:       id,
+	})
+	if err != nil {
+		p.t.Fatalf("unable to import %s: %s", typeName, err)
+	}
+	p.checkDiagnostics("ImportResourceState", resp.Diagnostics)
+	if len(resp.ImportedResources) != 1 {
+		p.t.Fatalf("expected 1 imported resource, got %d", len(resp.ImportedResources))
+	}
+
+	return p.read(typeName, p.value(typ, resp.ImportedResources[0].State))
+}
+
+// importStateError imports the resource with an invalid identifier and returns
+// the summary of the error it fails with.
+func (p *testProvider) importStateError(typeName string, id string) string {
+	p.t.Helper()
+
+	resp, err := p.server.ImportResourceState(context.Background(), &tfprotov6.ImportResourceStateRequest{
+		TypeName: typeName,
+		ID:       id,
+	})
+	if err != nil {
+		p.t.Fatalf("unable to import %s: %s", typeName, err)
+	}
+	for _, d := range resp.Diagnostics {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			return d.Summary
+		}
+	}
+	p.t.Fatalf("expected import of %s with identifier %q to fail", typeName, id)
+	return ""
+}
+
+// objectValue builds an object of the given type, attributes which are not
+// given are null, or empty for lists of nested blocks.
+func objectValue(typ tftypes.Object, attributes map[string]tftypes.Value) tftypes.Value {
+	vals := map[string]tftypes.Value{}
+	for k, t := range typ.AttributeTypes {
+		if v, ok := attributes[k]; ok {
+			vals[k] = v
+		} else if l, ok := t.(tftypes.List); ok {
+			vals[k] = tftypes.NewValue(l, []tftypes.Value{})
+		} else {
+			vals[k] = tftypes.NewValue(t, nil)
+		}
+	}
+	return tftypes.NewValue(typ, vals)
+}
+
+// proposedNewState mimics Terraform by carrying computed top-level attributes
+// which are not set in config over from the prior state.
+func proposedNewState(block *tfprotov6.SchemaBlock, prior tftypes.Value, config tftypes.Value) tftypes.Value {
+	if prior.IsNull() || config.IsNull() {
+		return config
+	}
+
+	var priorVals, configVals map[string]tftypes.Value
+	_ = prior.As(&priorVals)
+	_ = config.As(&configVals)
+	// the map shares its storage with config, which must stay as it is
+	proposed := map[string]tftypes.Value{}
+	for k, v := range configVals {
+		proposed[k] = v
+	}
+	for _, a := range block.Attributes {
+		if a.Computed && configVals[a.Name].IsNull() {
+			proposed[a.Name] = priorVals[a.Name]
+		}
+	}
+	return tftypes.NewValue(config.Type(), proposed)
+}
+
+// attribute returns the value of a top-level attribute of an object.
+func attribute(t *testing.T, object tftypes.Value, name string) tftypes.Value {
+	t.Helper()
+
+	var vals map[string]tftypes.Value
+	if err := object.As(&vals); err != nil {
+		t.Fatalf("unable to convert object: %s", err)
+	}
+	v, ok := vals[name]
+	if !ok {
+		t.Fatalf("attribute %s does not exist", name)
+	}
+	return v
+}
+
+// stringAttribute returns the value of a top-level string attribute of an
+// object.
+func stringAttribute(t *testing.T, object tftypes.Value, name string) string {
+	t.Helper()
+
+	var s string
+	if err := attribute(t, object, name).As(&s); err != nil {
+		t.Fatalf("unable to convert attribute %s: %s", name, err)
+	}
+	return s
+}
+
+// int64Attribute returns the value of a top-level number attribute of an
+// object.
+func int64Attribute(t *testing.T, object tftypes.Value, name string) int64 {
+	t.Helper()
+
+	var f big.Float
+	if err := attribute(t, object, name).As(&f); err != nil {
+		t.Fatalf("unable to convert attribute %s: %s", name, err)
+	}
+	i, _ := f.Int64()
+	return i
+}
+
+func stringValue(s string) tftypes.Value {
+	return tftypes.NewValue(tftypes.String, s)
+}
+
+func TestParseTimeout(t *testing.T) {
+	for _, tc := range []struct {
+		value types.String
+		want  time.Duration
+		err   bool
+	}{
+		{value: types.StringNull(), want: 0},
+		{value: types.StringValue(""), want: 0},
+		{value: types.StringValue("30s"), want: 30 * time.Second},
+		{value: types.StringValue("1h30m"), want: 90 * time.Minute},
+		{value: types.StringValue("0s"), err: true},
+		{value: types.StringValue("0"), err: true},
+		{value: types.StringValue("-1m"), err: true},
+		{value: types.StringValue("30"), err: true},
+		{value: types.StringValue("soon"), err: true},
+	} {
+		var diags diag.Diagnostics
+		got := parseTimeout(&diags, "read_timeout", tc.value)
+		if diags.HasError() != tc.err {
+			t.Errorf("parseTimeout(%s): expected error %t, got %v", tc.value, tc.err, diags)
+			continue
+		}
+		if tc.err && !diags.Errors()[0].(diag.DiagnosticWithPath).Path().Equal(path.Root("read_timeout")) {
+			t.Errorf("parseTimeout(%s): expected the error on read_timeout, got %v", tc.value, diags)
+		}
+		if got != tc.want {
+			t.Errorf("parseTimeout(%s) = %s, want %s", tc.value, got, tc.want)
+		}
+	}
+}
+
+// deadlineRecorder returns an HTTP client of the mock API, which records the
+// time left until the deadline of every request, or zero without a deadline.
+func deadlineRecorder(mock *acctest.Server) (*http.Client, *[]time.Duration) {
+	var deadlines []time.Duration
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var left time.Duration
+		if deadline, ok := req.Context().Deadline(); ok {
+			left = time.Until(deadline)
+		}
+		deadlines = append(deadlines, left)
+		return http.DefaultTransport.RoundTrip(req)
+	})}, &deadlines
+}
+
+func TestProviderTimeouts(t *testing.T) {
+	mock := acctest.NewServer()
+	t.Cleanup(mock.Close)
+	httpClient, deadlines := deadlineRecorder(mock)
+	p := configureTestProvider(t, mock.URL, "acctest", httpClient, map[string]tftypes.Value{
+		"read_timeout":  stringValue("1h"),
+		"write_timeout": stringValue("2h"),
+	})
+	typ := p.resourceType("startrail_service")
+
+	within := func(operation string, timeout time.Duration) {
+		t.Helper()
+		if len(*deadlines) == 0 {
+			t.Fatalf("%s: expected requests", operation)
+		}
+		for _, left := range *deadlines {
+			if left <= timeout-time.Minute || left > timeout {
+				t.Errorf("%s: expected a deadline within %s, got %s", operation, timeout, left)
+			}
+		}
+		*deadlines = nil
+	}
+
+	state := p.apply("startrail_service", tftypes.NewValue(typ, nil), testServiceConfig(typ, "A service which tells hello world"))
+	within("create", 2*time.Hour)
+	p.read("startrail_service", state)
+	within("read", time.Hour)
+	p.apply("startrail_service", state, tftypes.NewValue(typ, nil))
+	within("delete", 2*time.Hour)
+}
+
+func TestProviderTimeouts_unset(t *testing.T) {
+	mock := acctest.NewServer()
+	t.Cleanup(mock.Close)
+	httpClient, deadlines := deadlineRecorder(mock)
+	p := configureTestProvider(t, mock.URL, "acctest", httpClient, nil)
+	typ := p.resourceType("startrail_service")
+
+	state := p.apply("startrail_service", tftypes.NewValue(typ, nil), testServiceConfig(typ, "A service which tells hello world"))
+	p.read("startrail_service", state)
+	for _, left := range *deadlines {
+		if left != 0 {
+			t.Errorf("expected no deadline without timeouts, got %s", left)
+		}
+	}
+}
+
+func TestProviderTimeouts_unknown(t *testing.T) {
+	for _, attribute := range []string{"read_timeout", "write_timeout"} {
+		p, mock := newTestProvider(t)
+		typ := p.resourceType("startrail_service")
+		state := p.apply("startrail_service", tftypes.NewValue(typ, nil), testServiceConfig(typ, "A service which tells hello world"))
+
+		// an unknown timeout does not mean no timeout, the provider waits
+		// until it is known
+		unknown := configureTestProvider(t, mock.URL, "acctest", nil, map[string]tftypes.Value{
+			attribute: tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		})
+		service, _ := mock.Service("default", "development", "hello-world")
+		service.Description = "Changed outside of Terraform"
+		mock.PutService(service)
+
+		if got := stringAttribute(t, unknown.read("startrail_service", state), "description"); got != "A service which tells hello world" {
+			t.Errorf("%s: expected the prior state to be kept, got description %q", attribute, got)
+		}
+		if d := unknown.applyError("startrail_service", state, tftypes.NewValue(typ, nil)); d.Summary != "Provider Not Configured" {
+			t.Errorf("%s: unexpected error: %s", attribute, d.Summary)
+		}
+	}
+}
+
+func TestProviderConfigure_unknownCredentials(t *testing.T) {
+	p, mock := newTestProvider(t)
+	typ := p.resourceType("startrail_service")
+	state := p.apply("startrail_service", tftypes.NewValue(typ, nil), testServiceConfig(typ, "A service which tells hello world"))
+
+	// the api key may come from a resource which is not created yet
+	unknown := configureTestProvider(t, mock.URL, "", nil, map[string]tftypes.Value{
+		"api_key": tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+	})
+	service, _ := mock.Service("default", "development", "hello-world")
+	service.Description = "Changed outside of Terraform"
+	mock.PutService(service)
+
+	reads := mock.Requests(http.MethodGet, "/api/v1/service/default/development/hello-world")
+	if got := stringAttribute(t, unknown.read("startrail_service", state), "description"); got != "A service which tells hello world" {
+		t.Errorf("expected the prior state to be kept, got description %q", got)
+	}
+	if got := mock.Requests(http.MethodGet, "/api/v1/service/default/development/hello-world"); got != reads {
+		t.Errorf("expected no requests without a configured client, got %d", got-reads)
+	}
+	if d := unknown.applyError("startrail_service", state, tftypes.NewValue(typ, nil)); d.Summary != "Provider Not Configured" {
+		t.Errorf("unexpected error: %s", d.Summary)
+	}
+}
+
+func TestProviderConfigure_credentials(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config map[string]tftypes.Value
+		env    map[string]string
+		want   string
+	}{
+		{
+			name:   "token beats api_key",
+			config: map[string]tftypes.Value{"token": stringValue("config-token"), "api_key": stringValue("config-key")},
+			env:    map[string]string{"STARTRAIL_TOKEN": "env-token", "STARTRAIL_API_KEY": "env-key"},
+			want:   "Bearer config-token",
+		},
+		{
+			name:   "api_key beats the environment",
+			config: map[string]tftypes.Value{"api_key": stringValue("config-key")},
+			env:    map[string]string{"STARTRAIL_TOKEN": "env-token", "STARTRAIL_API_KEY": "env-key"},
+			want:   "apiKey config-key",
+		},
+		{
+			name: "STARTRAIL_TOKEN beats STARTRAIL_API_KEY",
+			env:  map[string]string{"STARTRAIL_TOKEN": "env-token", "STARTRAIL_API_KEY": "env-key"},
+			want: "Bearer env-token",
+		},
+		{
+			name: "STARTRAIL_API_KEY",
+			env:  map[string]string{"STARTRAIL_TOKEN": "", "STARTRAIL_API_KEY": "env-key"},
+			want: "apiKey env-key",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			mock := acctest.NewServer()
+			t.Cleanup(mock.Close)
+
+			var authorization []string
+			p := configureTestProvider(t, mock.URL, "", &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				authorization = append(authorization, req.Header.Get("Authorization"))
+				return http.DefaultTransport.RoundTrip(req)
+			})}, tc.config)
+			typ := p.resourceType("startrail_service")
+			p.apply("startrail_service", tftypes.NewValue(typ, nil), testServiceConfig(typ, "A service which tells hello world"))
+
+			if len(authorization) == 0 {
+				t.Fatal("expected requests")
+			}
+			for _, got := range authorization {
+				if got != tc.want {
+					t.Errorf("expected authorization %q, got %q", tc.want, got)
+				}
+			}
+		})
+	}
+}
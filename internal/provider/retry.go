@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+const (
+	// retryBaseDelay is the delay before the first retry, doubled on every
+	// following attempt up to retryMaxDelay.
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 10 * time.Second
+	// retryMaxDuration caps the total time spent retrying a single call.
+	retryMaxDuration = 2 * time.Minute
+)
+
+// retryNow, retrySleep and retryJitter are the clock, the timer and the source
+// of randomness of retryTransient, they are replaced in tests.
+var (
+	retryNow    = time.Now
+	retrySleep  = sleepContext
+	retryJitter = func(delay time.Duration) time.Duration {
+		// full jitter, so that parallel operations do not retry in lockstep
+		return time.Duration(rand.Int63n(int64(delay)))
+	}
+)
+
+// sleepContext waits for d, and reports false if ctx is done before.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// isTransient reports whether the response is a transient control plane
+// failure which is worth retrying.
+func isTransient(httpResp *http.Response) bool {
+	if httpResp == nil {
+		return false
+	}
+	switch httpResp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAttemptKey marks the context of the requests retrying an operation.
+type retryAttemptKey struct{}
+
+// isRetryAttempt reports whether ctx is the context of a retry of a failed
+// request, rather than the first attempt of an operation.
+func isRetryAttempt(ctx context.Context) bool {
+	attempt, _ := ctx.Value(retryAttemptKey{}).(int)
+	return attempt > 1
+}
+
+// retryTransient calls execute, typically building an SDK request with the
+// given context and calling its Execute method, and retries it with jittered
+// exponential backoff while it fails with a 502, 503 or 504 response, for at
+// most retryMaxDuration. Retries are sent with a context marked as such, so
+// that the circuit breaker counts a failed operation only once.
+func retryTransient[T any](ctx context.Context, execute func(ctx context.Context) (T, *http.Response, error)) (T, *http.Response, error) {
+	deadline := retryNow().Add(retryMaxDuration)
+	delay := retryBaseDelay
+
+	for attempt := 1; ; attempt++ {
+		v, httpResp, err := execute(context.WithValue(ctx, retryAttemptKey{}, attempt))
+		if !isTransient(httpResp) {
+			return v, httpResp, err
+		}
+
+		wait := retryJitter(delay)
+		if retryNow().Add(wait).After(deadline) {
+			return v, httpResp, err
+		}
+		tflog.Debug(ctx, "retrying transient Startrail API failure", map[string]interface{}{
+			"attempt": attempt,
+			"status":  httpResp.StatusCode,
+			"wait":    wait.String(),
+		})
+
+		if !retrySleep(ctx, wait) {
+			return v, httpResp, err
+		}
+
+		delay *= 2
+		if delay > retryMaxDelay {
+			delay = retryMaxDelay
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fakeRetryClock replaces the clock and timer of retryTransient with a fake
+// clock, which sleeping advances, and disables the jitter. It returns the
+// waits between the attempts.
+func fakeRetryClock(t *testing.T) *[]time.Duration {
+	now, sleep, jitter := retryNow, retrySleep, retryJitter
+	t.Cleanup(func() {
+		retryNow, retrySleep, retryJitter = now, sleep, jitter
+	})
+
+	var waits []time.Duration
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	retryNow = func() time.Time { return clock }
+	retrySleep = func(ctx context.Context, d time.Duration) bool {
+		waits = append(waits, d)
+		clock = clock.Add(d)
+		return ctx.Err() == nil
+	}
+	retryJitter = func(delay time.Duration) time.Duration { return delay }
+	return &waits
+}
+
+// respondWith returns an execute function for retryTransient which responds
+// with the given statuses, and with the last one once they are exhausted.
+func respondWith(attempts *int, statuses ...int) func(ctx context.Context) (struct{}, *http.Response, error) {
+	return func(ctx context.Context) (struct{}, *http.Response, error) {
+		status := statuses[len(statuses)-1]
+		if *attempts < len(statuses) {
+			status = statuses[*attempts]
+		}
+		*attempts++
+		return struct{}{}, &http.Response{StatusCode: status}, nil
+	}
+}
+
+func TestRetryTransient_statuses(t *testing.T) {
+	fakeRetryClock(t)
+
+	for _, tc := range []struct {
+		status int
+		retry  bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusBadRequest, false},
+		{http.StatusNotFound, false},
+		{http.StatusConflict, false},
+		{http.StatusTooManyRequests, false},
+		{http.StatusInternalServerError, false},
+		{http.StatusNotImplemented, false},
+		{http.StatusBadGateway, true},
+		{http.StatusServiceUnavailable, true},
+		{http.StatusGatewayTimeout, true},
+	} {
+		attempts := 0
+		_, httpResp, _ := retryTransient(context.Background(), respondWith(&attempts, tc.status, http.StatusOK))
+		want := 1
+		if tc.retry {
+			want = 2
+		}
+		if attempts != want {
+			t.Errorf("status %d: expected %d attempts, got %d", tc.status, want, attempts)
+		}
+		if tc.retry && httpResp.StatusCode != http.StatusOK {
+			t.Errorf("status %d: expected the response of the retry, got %d", tc.status, httpResp.StatusCode)
+		}
+	}
+}
+
+func TestRetryTransient_backoff(t *testing.T) {
+	waits := fakeRetryClock(t)
+
+	attempts := 0
+	_, httpResp, _ := retryTransient(context.Background(), respondWith(&attempts,
+		503, 503, 503, 503, 503, 503, 503, 503, http.StatusOK,
+	))
+	if attempts != 9 || httpResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected success after 9 attempts, got %d attempts and status %d", attempts, httpResp.StatusCode)
+	}
+
+	// doubled from the base delay, and capped at the maximum delay
+	want := []time.Duration{
+		500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second,
+		retryMaxDelay, retryMaxDelay, retryMaxDelay,
+	}
+	if len(*waits) != len(want) {
+		t.Fatalf("expected waits %v, got %v", want, *waits)
+	}
+	for i := range want {
+		if (*waits)[i] != want[i] {
+			t.Errorf("expected waits %v, got %v", want, *waits)
+			break
+		}
+	}
+}
+
+func TestRetryTransient_jitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if wait := retryJitter(retryBaseDelay); wait < 0 || wait >= retryBaseDelay {
+			t.Fatalf("expected a jittered wait below %s, got %s", retryBaseDelay, wait)
+		}
+	}
+}
+
+func TestRetryTransient_maxDuration(t *testing.T) {
+	waits := fakeRetryClock(t)
+
+	attempts := 0
+	_, httpResp, _ := retryTransient(context.Background(), respondWith(&attempts, http.StatusGatewayTimeout))
+	if httpResp.StatusCode != http.StatusGatewayTimeout {
+		t.Errorf("expected the last transient response, got %d", httpResp.StatusCode)
+	}
+
+	var total time.Duration
+	for _, w := range *waits {
+		total += w
+	}
+	if total > retryMaxDuration {
+		t.Errorf("expected to retry for at most %s, waited %s", retryMaxDuration, total)
+	}
+	// another wait of the maximum delay would exceed the limit
+	if total+retryMaxDelay <= retryMaxDuration {
+		t.Errorf("expected to retry until close to %s, gave up after %s", retryMaxDuration, total)
+	}
+	if attempts != len(*waits)+1 {
+		t.Errorf("expected an attempt after every wait, got %d attempts and %d waits", attempts, len(*waits))
+	}
+}
+
+func TestRetryTransient_cancelled(t *testing.T) {
+	waits := fakeRetryClock(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	attempts := 0
+	_, httpResp, _ := retryTransient(ctx, respondWith(&attempts, http.StatusServiceUnavailable))
+	if attempts != 1 || len(*waits) != 1 {
+		t.Errorf("expected to give up in the first wait, got %d attempts and %d waits", attempts, len(*waits))
+	}
+	if httpResp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected the transient response, got %d", httpResp.StatusCode)
+	}
+}
@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// selectorOperator is the operator of a single label selector requirement.
+type selectorOperator string
+
+const (
+	selectorExists       selectorOperator = "exists"
+	selectorDoesNotExist selectorOperator = "!"
+	selectorEquals       selectorOperator = "="
+	selectorNotEquals    selectorOperator = "!="
+	selectorIn           selectorOperator = "in"
+	selectorNotIn        selectorOperator = "notin"
+)
+
+// selectorRequirement is a single comma separated requirement of a label
+// selector, such as team=platform or tier in (web, api).
+type selectorRequirement struct {
+	key      string
+	operator selectorOperator
+	values   []string
+}
+
+// parseSelector parses a label selector. Requirements are separated by commas
+// and must all match:
+//
+//	key              the label is set
+//	!key             the label is not set
+//	key=value        the label is set to value, also written key==value
+//	key!=value       the label is not set to value, or not set at all
+//	key in (a, b)    the label is set to one of the values
+//	key notin (a, b) the label is not set to any of the values, or not set
+//
+// An empty selector matches all labels.
+func parseSelector(selector string) ([]selectorRequirement, error) {
+	var requirements []selectorRequirement
+	for _, part := range splitSelector(selector) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			if strings.TrimSpace(selector) == "" {
+				continue
+			}
+			return nil, fmt.Errorf("empty requirement in %q", selector)
+		}
+		r, err := parseRequirement(part)
+		if err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, r)
+	}
+	return requirements, nil
+}
+
+// splitSelector splits a selector at the commas which are not within the
+// value list of a set based requirement.
+func splitSelector(selector string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, selector[start:])
+}
+
+func parseRequirement(s string) (selectorRequirement, error) {
+	if strings.HasPrefix(s, "!") {
+		key := strings.TrimSpace(s[1:])
+		if err := validateSelectorKey(key); err != nil {
+			return selectorRequirement{}, fmt.Errorf("invalid requirement %q: %w", s, err)
+		}
+		return selectorRequirement{key: key, operator: selectorDoesNotExist}, nil
+	}
+
+	if i := strings.Index(s, "("); i >= 0 {
+		fields := strings.Fields(s[:i])
+		if len(fields) != 2 || (fields[1] != string(selectorIn) && fields[1] != string(selectorNotIn)) {
+			return selectorRequirement{}, fmt.Errorf("invalid requirement %q: expected key in (values) or key notin (values)", s)
+		}
+		if !strings.HasSuffix(s, ")") || strings.Count(s, "(") != 1 || strings.Count(s, ")") != 1 {
+			return selectorRequirement{}, fmt.Errorf("invalid requirement %q: unbalanced parentheses", s)
+		}
+		if err := validateSelectorKey(fields[0]); err != nil {
+			return selectorRequirement{}, fmt.Errorf("invalid requirement %q: %w", s, err)
+		}
+		var values []string
+		for _, v := range strings.Split(s[i+1:len(s)-1], ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			return selectorRequirement{}, fmt.Errorf("invalid requirement %q: expected at least one value", s)
+		}
+		return selectorRequirement{key: fields[0], operator: selectorOperator(fields[1]), values: values}, nil
+	}
+
+	for _, op := range []string{"!=", "==", "="} {
+		if i := strings.Index(s, op); i >= 0 {
+			key, value := strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+len(op):])
+			if err := validateSelectorKey(key); err != nil {
+				return selectorRequirement{}, fmt.Errorf("invalid requirement %q: %w", s, err)
+			}
+			if strings.ContainsAny(value, "=!() ") {
+				return selectorRequirement{}, fmt.Errorf("invalid requirement %q: invalid value %q", s, value)
+			}
+			operator := selectorEquals
+			if op == "!=" {
+				operator = selectorNotEquals
+			}
+			return selectorRequirement{key: key, operator: operator, values: []string{value}}, nil
+		}
+	}
+
+	if err := validateSelectorKey(s); err != nil {
+		return selectorRequirement{}, fmt.Errorf("invalid requirement %q: %w", s, err)
+	}
+	return selectorRequirement{key: s, operator: selectorExists}, nil
+}
+
+func validateSelectorKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("missing label key")
+	}
+	if strings.ContainsAny(key, "=!(), \t") {
+		return fmt.Errorf("invalid label key %q", key)
+	}
+	return nil
+}
+
+// matchesSelector reports whether the labels satisfy all requirements.
+func matchesSelector(labels map[string]string, requirements []selectorRequirement) bool {
+	for _, r := range requirements {
+		if !r.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r selectorRequirement) matches(labels map[string]string) bool {
+	value, ok := labels[r.key]
+	switch r.operator {
+	case selectorExists:
+		return ok
+	case selectorDoesNotExist:
+		return !ok
+	case selectorEquals, selectorIn:
+		return ok && r.hasValue(value)
+	case selectorNotEquals, selectorNotIn:
+		return !ok || !r.hasValue(value)
+	}
+	return false
+}
+
+func (r selectorRequirement) hasValue(value string) bool {
+	for _, v := range r.values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,348 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+	"strings"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &ServiceAccessResource{}
+var _ resource.ResourceWithImportState = &ServiceAccessResource{}
+
+func NewServiceAccessResource() resource.Resource {
+	return &ServiceAccessResource{}
+}
+
+// ServiceAccessResource manages a single access entry of an existing service.
+type ServiceAccessResource struct {
+	client *StartrailProviderClient
+}
+
+// ServiceAccessModel describes the resource data model.
+type ServiceAccessModel struct {
+	Id          types.String `tfsdk:"id"`
+	Service     types.String `tfsdk:"service"`
+	Environment types.String `tfsdk:"environment"`
+	Endpoint    types.String `tfsdk:"endpoint"`
+	Auth        types.Bool   `tfsdk:"auth"`
+	Internal    types.Bool   `tfsdk:"internal"`
+}
+
+func (r *ServiceAccessResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_service_access"
+}
+
+func (r *ServiceAccessResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Manages a single access entry on an existing service. " +
+			"The service may be managed by a `startrail_service` resource, as long as the endpoint is not declared in one of its `access` blocks as well.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Access entry identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"service": schema.StringAttribute{
+				MarkdownDescription: "Name of the service to add the access entry to",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"environment": schema.StringAttribute{
+				MarkdownDescription: "Service environment",
+				Required:            true,
+				Validators:          []validator.String{environmentValidator{}},
+				PlanModifiers:       environmentPlanModifiers(),
+			},
+			"endpoint": schema.StringAttribute{
+				MarkdownDescription: "The upstream endpoint to use for API requests.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"auth": schema.BoolAttribute{
+				MarkdownDescription: "Set to true if this endpoint requires authentication to connect",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"internal": schema.BoolAttribute{
+				MarkdownDescription: "Set to true if this endpoint is internal to the cluster",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+		},
+	}
+}
+
+func (r *ServiceAccessResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*StartrailProviderClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *StartrailProviderClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+func (r *ServiceAccessResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	ctx, cancel := r.client.writeContext(ctx)
+	defer cancel()
+
+	var data ServiceAccessModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	environment := data.Environment.ValueString()
+	name := data.Service.ValueString()
+	defer r.client.lockService(environment, name)()
+
+	service, diags := r.client.getService(ctx, environment, name)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if service == nil {
+		addServiceNotFoundError(&resp.Diagnostics, environment, name)
+		return
+	}
+
+	for _, a := range service.Access {
+		if a.Endpoint == data.Endpoint.ValueString() {
+			resp.Diagnostics.AddError(
+				"Access Entry Already Exists",
+				fmt.Sprintf("The service %q already has an access entry for %q. Import it to manage it with Terraform.", name, a.Endpoint),
+			)
+			return
+		}
+	}
+
+	service.Access = append(service.Access, bindings.Access{
+		Auth:     data.Auth.ValueBool(),
+		Endpoint: data.Endpoint.ValueString(),
+		Internal: data.Internal.ValueBool(),
+	})
+
+	_, diags = r.client.putService(ctx, "update service", *service)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Id = types.StringValue(fmt.Sprintf("%s/%s/%s/%s", r.client.Tenant, environment, name, data.Endpoint.ValueString()))
+
+	tflog.Trace(ctx, "created a service access entry")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ServiceAccessResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Keep the prior state until the provider configuration is known.
+	if r.client == nil {
+		return
+	}
+
+	ctx, cancel := r.client.readContext(ctx)
+	defer cancel()
+
+	var data ServiceAccessModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	service, diags := r.client.getService(ctx, data.Environment.ValueString(), data.Service.ValueString())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if service == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	found := false
+	for _, a := range service.Access {
+		if a.Endpoint == data.Endpoint.ValueString() {
+			data.Auth = types.BoolValue(a.Auth)
+			data.Internal = types.BoolValue(a.Internal)
+			found = true
+			break
+		}
+	}
+	if !found {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ServiceAccessResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	ctx, cancel := r.client.writeContext(ctx)
+	defer cancel()
+
+	var data ServiceAccessModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	environment := data.Environment.ValueString()
+	name := data.Service.ValueString()
+	defer r.client.lockService(environment, name)()
+
+	service, diags := r.client.getService(ctx, environment, name)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if service == nil {
+		addServiceNotFoundError(&resp.Diagnostics, environment, name)
+		return
+	}
+
+	entry := bindings.Access{
+		Auth:     data.Auth.ValueBool(),
+		Endpoint: data.Endpoint.ValueString(),
+		Internal: data.Internal.ValueBool(),
+	}
+	found := false
+	for i, a := range service.Access {
+		if a.Endpoint == entry.Endpoint {
+			service.Access[i] = entry
+			found = true
+			break
+		}
+	}
+	if !found {
+		service.Access = append(service.Access, entry)
+	}
+
+	_, diags = r.client.putService(ctx, "update service", *service)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ServiceAccessResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	ctx, cancel := r.client.writeContext(ctx)
+	defer cancel()
+
+	var data ServiceAccessModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	environment := data.Environment.ValueString()
+	name := data.Service.ValueString()
+	defer r.client.lockService(environment, name)()
+
+	service, diags := r.client.getService(ctx, environment, name)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || service == nil {
+		return
+	}
+
+	access := []bindings.Access{}
+	for _, a := range service.Access {
+		if a.Endpoint != data.Endpoint.ValueString() {
+			access = append(access, a)
+		}
+	}
+	if len(access) == len(service.Access) {
+		return
+	}
+	service.Access = access
+
+	_, diags = r.client.putService(ctx, "update service", *service)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *ServiceAccessResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	// the endpoint is last as it may contain slashes itself
+	parts := strings.SplitN(req.ID, "/", 4)
+	if len(parts) != 4 || parts[0] == "" || parts[1] == "" || parts[2] == "" || parts[3] == "" {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: tenant/environment/service/endpoint. Got: %q", req.ID),
+		)
+		return
+	}
+	if !r.client.checkImportTenant(&resp.Diagnostics, parts[0]) {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("environment"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("service"), parts[2])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("endpoint"), parts[3])...)
+}
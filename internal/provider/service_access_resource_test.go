@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+)
+
+func TestServiceAccessResource(t *testing.T) {
+	p, mock := newTestProvider(t)
+	typ := p.resourceType("startrail_service_access")
+
+	mock.PutService(bindings.Service{
+		Name:        "hello-world",
+		Environment: "development",
+		Tenant:      "default",
+		Access: []bindings.Access{
+			{Endpoint: "https://example.com/owned-elsewhere"},
+		},
+	})
+	config := func(auth bool) tftypes.Value {
+		return objectValue(typ, map[string]tftypes.Value{
+			"service":     stringValue("hello-world"),
+			"environment": stringValue("development"),
+			"endpoint":    stringValue("https://example.com/hello"),
+			"auth":        tftypes.NewValue(tftypes.Bool, auth),
+		})
+	}
+
+	// create
+	state := p.apply("startrail_service_access", tftypes.NewValue(typ, nil), config(false))
+	if got := stringAttribute(t, state, "id"); got != "default/development/hello-world/https://example.com/hello" {
+		t.Errorf("unexpected id: %s", got)
+	}
+	service, _ := mock.Service("default", "development", "hello-world")
+	if len(service.Access) != 2 {
+		t.Fatalf("expected 2 access entries, got %+v", service.Access)
+	}
+
+	// update
+	state = p.apply("startrail_service_access", state, config(true))
+	service, _ = mock.Service("default", "development", "hello-world")
+	if len(service.Access) != 2 || !service.Access[1].Auth {
+		t.Errorf("expected access entry to require auth, got %+v", service.Access)
+	}
+
+	// import
+	imported := p.importState("startrail_service_access", stringAttribute(t, state, "id"))
+	var auth bool
+	if err := attribute(t, imported, "auth").As(&auth); err != nil || !auth {
+		t.Errorf("expected imported access entry to require auth, got %v (%v)", auth, err)
+	}
+	if got := p.importStateError("startrail_service_access", "other/development/hello-world/https://example.com/hello"); got != "Unexpected Import Tenant" {
+		t.Errorf("expected the import of another tenant to fail, got %q", got)
+	}
+
+	// delete
+	p.apply("startrail_service_access", state, tftypes.NewValue(typ, nil))
+	service, _ = mock.Service("default", "development", "hello-world")
+	if len(service.Access) != 1 || service.Access[0].Endpoint != "https://example.com/owned-elsewhere" {
+		t.Errorf("expected only the unmanaged access entry to remain, got %+v", service.Access)
+	}
+
+	// read of a removed entry
+	if got := p.read("startrail_service_access", state); !got.IsNull() {
+		t.Errorf("expected state to be removed, got %s", got)
+	}
+}
+
+func TestServiceAccessResource_managedService(t *testing.T) {
+	p, mock := newTestProvider(t)
+	serviceType := p.resourceType("startrail_service")
+	typ := p.resourceType("startrail_service_access")
+
+	service := p.apply("startrail_service", tftypes.NewValue(serviceType, nil), testServiceConfig(serviceType, "A service which tells hello world"))
+	access := p.apply("startrail_service_access", tftypes.NewValue(typ, nil), objectValue(typ, map[string]tftypes.Value{
+		"service":     stringValue("hello-world"),
+		"environment": stringValue("development"),
+		"endpoint":    stringValue("https://example.com/attached"),
+	}))
+
+	// the attached entry is neither read into the state of the service, nor
+	// removed when the service is updated
+	service = p.read("startrail_service", service)
+	var entries []tftypes.Value
+	if err := attribute(t, service, "access").As(&entries); err != nil || len(entries) != 1 {
+		t.Errorf("expected 1 access block in the service state, got %d (%v)", len(entries), err)
+	}
+	p.apply("startrail_service", service, testServiceConfig(serviceType, "A service which tells hello"))
+
+	s, _ := mock.Service("default", "development", "hello-world")
+	if len(s.Access) != 2 {
+		t.Fatalf("expected 2 access entries after updating the service, got %+v", s.Access)
+	}
+	if got := p.read("startrail_service_access", access); got.IsNull() {
+		t.Error("expected the attached access entry to survive the update of the service")
+	}
+}
@@ -0,0 +1,326 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+	"strings"
+)
+
+// ServiceAttachmentModel describes the data model of the resources attaching
+// a labeled entry keyed by source to an existing service.
+type ServiceAttachmentModel struct {
+	Id          types.String `tfsdk:"id"`
+	Service     types.String `tfsdk:"service"`
+	Environment types.String `tfsdk:"environment"`
+	Source      types.String `tfsdk:"source"`
+	Labels      types.Map    `tfsdk:"labels"`
+}
+
+// serviceAttachment implements the resources attaching a labeled entry keyed
+// by source, such as a logging configuration, to an existing service. The
+// resources embed it and only add Metadata and Schema.
+type serviceAttachment[V any] struct {
+	client *StartrailProviderClient
+
+	// kind names the entry in messages, e.g. "logging configuration".
+	kind string
+	// entries returns the entries of the service the resource attaches to.
+	entries func(service *bindings.Service) *map[string]V
+	// newEntry and entryLabels convert between an entry and its labels.
+	newEntry    func(labels map[string]string) V
+	entryLabels func(entry V) map[string]string
+}
+
+// serviceAttachmentSchema returns the schema of an attachment resource.
+func serviceAttachmentSchema(description string, kind string) schema.Schema {
+	return schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: description,
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Attachment identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"service": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Name of the service to attach the %s to", kind),
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"environment": schema.StringAttribute{
+				MarkdownDescription: "Service environment",
+				Required:            true,
+				Validators:          []validator.String{environmentValidator{}},
+				PlanModifiers:       environmentPlanModifiers(),
+			},
+			"source": schema.StringAttribute{
+				MarkdownDescription: "The source to use for the service",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"labels": schema.MapAttribute{
+				MarkdownDescription: "Labels to apply to the service",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+		},
+	}
+}
+
+func (r *serviceAttachment[V]) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*StartrailProviderClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *StartrailProviderClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+func (r *serviceAttachment[V]) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	ctx, cancel := r.client.writeContext(ctx)
+	defer cancel()
+
+	var data ServiceAttachmentModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.modify(ctx, data, true, func(entries map[string]V) bool {
+		if _, ok := entries[data.Source.ValueString()]; ok {
+			resp.Diagnostics.AddError(
+				titleCase(r.kind)+" Already Exists",
+				fmt.Sprintf("The service %q already has a %s for %q. Import it to manage it with Terraform.", data.Service.ValueString(), r.kind, data.Source.ValueString()),
+			)
+			return false
+		}
+		entries[data.Source.ValueString()] = r.entry(ctx, data)
+		return true
+	})...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Id = types.StringValue(fmt.Sprintf("%s/%s/%s/%s", r.client.Tenant, data.Environment.ValueString(), data.Service.ValueString(), data.Source.ValueString()))
+
+	tflog.Trace(ctx, "created a service "+r.kind+" attachment")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *serviceAttachment[V]) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Keep the prior state until the provider configuration is known.
+	if r.client == nil {
+		return
+	}
+
+	ctx, cancel := r.client.readContext(ctx)
+	defer cancel()
+
+	var data ServiceAttachmentModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	service, diags := r.client.getService(ctx, data.Environment.ValueString(), data.Service.ValueString())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if service == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	entry, ok := (*r.entries(service))[data.Source.ValueString()]
+	if !ok {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// an unset labels argument is returned as an empty map by the backend
+	if l := r.entryLabels(entry); len(l) > 0 || !data.Labels.IsNull() {
+		labels := map[string]attr.Value{}
+		for k, v := range l {
+			labels[k] = types.StringValue(v)
+		}
+		m, d := types.MapValue(types.StringType, labels)
+		resp.Diagnostics.Append(d...)
+		data.Labels = m
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *serviceAttachment[V]) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	ctx, cancel := r.client.writeContext(ctx)
+	defer cancel()
+
+	var data ServiceAttachmentModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.modify(ctx, data, true, func(entries map[string]V) bool {
+		entries[data.Source.ValueString()] = r.entry(ctx, data)
+		return true
+	})...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *serviceAttachment[V]) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	ctx, cancel := r.client.writeContext(ctx)
+	defer cancel()
+
+	var data ServiceAttachmentModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// a service which is gone took the entry with it
+	resp.Diagnostics.Append(r.modify(ctx, data, false, func(entries map[string]V) bool {
+		if _, ok := entries[data.Source.ValueString()]; !ok {
+			return false
+		}
+		delete(entries, data.Source.ValueString())
+		return true
+	})...)
+}
+
+// modify runs a read-modify-write cycle of the entries of the service data
+// attaches to, under the lock of the service. The service is only written back
+// when change returns true. A missing service is an error when mustExist is
+// set, and nothing to do otherwise.
+func (r *serviceAttachment[V]) modify(ctx context.Context, data ServiceAttachmentModel, mustExist bool, change func(entries map[string]V) bool) diag.Diagnostics {
+	environment := data.Environment.ValueString()
+	name := data.Service.ValueString()
+	defer r.client.lockService(environment, name)()
+
+	service, diags := r.client.getService(ctx, environment, name)
+	if diags.HasError() {
+		return diags
+	}
+	if service == nil {
+		if mustExist {
+			addServiceNotFoundError(&diags, environment, name)
+		}
+		return diags
+	}
+
+	entries := r.entries(service)
+	if *entries == nil {
+		*entries = map[string]V{}
+	}
+	if !change(*entries) {
+		return diags
+	}
+
+	_, d := r.client.putService(ctx, "update service", *service)
+	diags.Append(d...)
+	return diags
+}
+
+// entry returns the entry described by data.
+func (r *serviceAttachment[V]) entry(ctx context.Context, data ServiceAttachmentModel) V {
+	labels := map[string]string{}
+	data.Labels.ElementsAs(ctx, &labels, true)
+	return r.newEntry(labels)
+}
+
+func (r *serviceAttachment[V]) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	parts := strings.SplitN(req.ID, "/", 4)
+	if len(parts) != 4 || parts[0] == "" || parts[1] == "" || parts[2] == "" || parts[3] == "" {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: tenant/environment/service/source. Got: %q", req.ID),
+		)
+		return
+	}
+	if !r.client.checkImportTenant(&resp.Diagnostics, parts[0]) {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("environment"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("service"), parts[2])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("source"), parts[3])...)
+}
+
+// titleCase capitalizes every word of s, for use in diagnostic summaries.
+func titleCase(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
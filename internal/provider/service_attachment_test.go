@@ -0,0 +1,130 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+)
+
+// testServiceAttachment exercises an attachment resource of the given type
+// against a service which is not managed by Terraform. entries returns the
+// labels of the attached entries of a service by source.
+func testServiceAttachment(t *testing.T, typeName string, entries func(bindings.Service) map[string]map[string]string) {
+	p, mock := newTestProvider(t)
+	typ := p.resourceType(typeName)
+	labelsType := typ.AttributeTypes["labels"]
+
+	mock.PutService(bindings.Service{
+		Name:        "hello-world",
+		Environment: "development",
+		Tenant:      "default",
+		Access:      []bindings.Access{},
+		Logging:     map[string]bindings.Logging{"owned-elsewhere": {Labels: map[string]string{}}},
+		Sources:     map[string]bindings.Source{"owned-elsewhere": {Labels: map[string]string{}}},
+	})
+	config := func(app string) tftypes.Value {
+		return objectValue(typ, map[string]tftypes.Value{
+			"service":     stringValue("hello-world"),
+			"environment": stringValue("development"),
+			"source":      stringValue("loki"),
+			"labels": tftypes.NewValue(labelsType, map[string]tftypes.Value{
+				"app": stringValue(app),
+			}),
+		})
+	}
+	attached := func() map[string]map[string]string {
+		service, _ := mock.Service("default", "development", "hello-world")
+		return entries(service)
+	}
+
+	// create
+	state := p.apply(typeName, tftypes.NewValue(typ, nil), config("hello"))
+	if got := stringAttribute(t, state, "id"); got != "default/development/hello-world/loki" {
+		t.Errorf("unexpected id: %s", got)
+	}
+	if e := attached(); len(e) != 2 || e["loki"]["app"] != "hello" {
+		t.Fatalf("expected 2 entries with loki labeled app=hello, got %+v", e)
+	}
+
+	// read
+	state = p.read(typeName, state)
+	var labels map[string]tftypes.Value
+	if err := attribute(t, state, "labels").As(&labels); err != nil || len(labels) != 1 {
+		t.Errorf("expected 1 label after read, got %v (%v)", labels, err)
+	}
+
+	// update
+	state = p.apply(typeName, state, config("world"))
+	if e := attached(); len(e) != 2 || e["loki"]["app"] != "world" {
+		t.Errorf("expected loki to be labeled app=world, got %+v", e)
+	}
+
+	// import
+	imported := p.importState(typeName, stringAttribute(t, state, "id"))
+	if err := attribute(t, imported, "labels").As(&labels); err != nil || len(labels) != 1 {
+		t.Errorf("expected 1 imported label, got %v (%v)", labels, err)
+	}
+	if got := p.importStateError(typeName, "other/development/hello-world/loki"); got != "Unexpected Import Tenant" {
+		t.Errorf("expected the import of another tenant to fail, got %q", got)
+	}
+
+	// delete
+	p.apply(typeName, state, tftypes.NewValue(typ, nil))
+	if e := attached(); len(e) != 1 || e["owned-elsewhere"] == nil {
+		t.Errorf("expected only the unmanaged entry to remain, got %+v", e)
+	}
+
+	// read of a removed entry
+	if got := p.read(typeName, state); !got.IsNull() {
+		t.Errorf("expected state to be removed, got %s", got)
+	}
+}
+
+// testServiceAttachment_managedService checks that the entry of an attachment
+// resource survives updates of a startrail_service resource declaring other
+// entries in block.
+func testServiceAttachment_managedService(t *testing.T, typeName string, block string, entries func(bindings.Service) map[string]map[string]string) {
+	p, mock := newTestProvider(t)
+	serviceType := p.resourceType("startrail_service")
+	typ := p.resourceType(typeName)
+
+	blockType := serviceType.AttributeTypes[block].(tftypes.List)
+	serviceConfig := func(description string) tftypes.Value {
+		var vals map[string]tftypes.Value
+		_ = testServiceConfig(serviceType, description).As(&vals)
+		vals[block] = tftypes.NewValue(blockType, []tftypes.Value{
+			objectValue(blockType.ElementType.(tftypes.Object), map[string]tftypes.Value{
+				"source": stringValue("stdout"),
+			}),
+		})
+		return tftypes.NewValue(serviceType, vals)
+	}
+
+	service := p.apply("startrail_service", tftypes.NewValue(serviceType, nil), serviceConfig("A service which tells hello world"))
+	attachment := p.apply(typeName, tftypes.NewValue(typ, nil), objectValue(typ, map[string]tftypes.Value{
+		"service":     stringValue("hello-world"),
+		"environment": stringValue("development"),
+		"source":      stringValue("loki"),
+	}))
+
+	// the attached entry is neither read into the state of the service, nor
+	// removed when the service is updated
+	service = p.read("startrail_service", service)
+	var blocks []tftypes.Value
+	if err := attribute(t, service, block).As(&blocks); err != nil || len(blocks) != 1 {
+		t.Errorf("expected 1 %s block in the service state, got %d (%v)", block, len(blocks), err)
+	}
+	p.apply("startrail_service", service, serviceConfig("A service which tells hello"))
+
+	s, _ := mock.Service("default", "development", "hello-world")
+	if e := entries(s); len(e) != 2 {
+		t.Fatalf("expected 2 entries after updating the service, got %+v", e)
+	}
+	if got := p.read(typeName, attachment); got.IsNull() {
+		t.Error("expected the attached entry to survive the update of the service")
+	}
+}
@@ -0,0 +1,248 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// serviceVisibleAttempts bounds how often a freshly created service is
+	// polled before giving up. The delay between the attempts doubles from
+	// serviceVisibleDelay up to retryMaxDelay.
+	serviceVisibleAttempts = 10
+	serviceVisibleDelay    = 500 * time.Millisecond
+
+	// bulkReadThreshold is the number of service reads after which the
+	// remaining reads of a refresh are served from a single list call.
+	bulkReadThreshold = 5
+)
+
+// serviceLocks serializes read-modify-write cycles against a single service,
+// so that resources attaching entries to the same service do not overwrite
+// each other's changes when Terraform applies them in parallel.
+type serviceLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func (l *serviceLocks) lock(key string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*sync.Mutex{}
+	}
+	m, ok := l.locks[key]
+	if !ok {
+		m = &sync.Mutex{}
+		l.locks[key] = m
+	}
+	l.mu.Unlock()
+
+	m.Lock()
+	return m.Unlock
+}
+
+// lockService acquires the lock for the given service and returns the function
+// that releases it.
+func (c *StartrailProviderClient) lockService(environment string, name string) func() {
+	return c.serviceLocks.lock(fmt.Sprintf("%s/%s/%s", c.Tenant, normalizeEnvironment(environment), name))
+}
+
+// serviceCache coalesces the reads of many services of the tenant into a
+// single call of the bulk list endpoint. It stays disabled for the first
+// bulkReadThreshold reads, so small configurations do not list large tenants.
+type serviceCache struct {
+	mu       sync.Mutex
+	reads    int
+	loading  chan struct{}
+	services map[string]bindings.Service
+	// disabled is set when the list call failed, so that it is not repeated
+	// for every following read.
+	disabled bool
+}
+
+// invalidate drops the listed services, it must be called after every write.
+func (c *serviceCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.services = nil
+	c.reads = 0
+	c.disabled = false
+}
+
+// readService returns a service like getService, serving it from the bulk
+// list result when many services are read. Only use it for refreshes, read-
+// modify-write cycles must call getService.
+func (c *StartrailProviderClient) readService(ctx context.Context, environment string, name string) (*bindings.Service, diag.Diagnostics) {
+	key := fmt.Sprintf("%s/%s", normalizeEnvironment(environment), name)
+	cache := &c.serviceCache
+
+	cache.mu.Lock()
+	cache.reads++
+	for cache.loading != nil {
+		loading := cache.loading
+		cache.mu.Unlock()
+		<-loading
+		cache.mu.Lock()
+	}
+	if cache.services != nil {
+		s, ok := cache.services[key]
+		cache.mu.Unlock()
+		if ok {
+			return &s, nil
+		}
+		// the service may have been created after the list call
+		return c.getService(ctx, environment, name)
+	}
+	if cache.reads <= bulkReadThreshold || cache.disabled {
+		cache.mu.Unlock()
+		return c.getService(ctx, environment, name)
+	}
+	loading := make(chan struct{})
+	cache.loading = loading
+	cache.mu.Unlock()
+
+	services, diags := c.listServices(ctx)
+
+	cache.mu.Lock()
+	cache.loading = nil
+	close(loading)
+	if diags.HasError() {
+		cache.disabled = true
+		cache.mu.Unlock()
+		tflog.Debug(ctx, "unable to list services, falling back to single reads")
+		return c.getService(ctx, environment, name)
+	}
+	cache.services = map[string]bindings.Service{}
+	for _, s := range services {
+		cache.services[fmt.Sprintf("%s/%s", normalizeEnvironment(s.Environment), s.Name)] = s
+	}
+	s, ok := cache.services[key]
+	cache.mu.Unlock()
+
+	if ok {
+		return &s, nil
+	}
+	return c.getService(ctx, environment, name)
+}
+
+// listServices fetches all services of the tenant.
+func (c *StartrailProviderClient) listServices(ctx context.Context) ([]bindings.Service, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	startrailResponse, execute, err := retryTransient(ctx, func(ctx context.Context) (*bindings.ServiceListResponse, *http.Response, error) {
+		return c.Client.ServiceAPI.List(ctx, c.Tenant).Execute()
+	})
+	if !handleStartrailResponse(&diags, "list services", startrailResponse.GetDiagnostics(), execute, err) {
+		return nil, diags
+	}
+
+	return startrailResponse.GetResponse(), diags
+}
+
+// getService fetches a service from the Startrail API. A nil service without
+// error diagnostics is returned when the service does not exist.
+func (c *StartrailProviderClient) getService(ctx context.Context, environment string, name string) (*bindings.Service, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	startrailResponse, execute, err := retryTransient(ctx, func(ctx context.Context) (*bindings.ServiceResponse, *http.Response, error) {
+		return c.Client.ServiceAPI.Get(ctx, c.Tenant, normalizeEnvironment(environment), name).Execute()
+	})
+	if execute != nil && execute.StatusCode == http.StatusNotFound {
+		return nil, diags
+	}
+	if !handleStartrailResponse(&diags, "read service", startrailResponse.GetDiagnostics(), execute, err) {
+		return nil, diags
+	}
+
+	s := startrailResponse.GetResponse()
+	return &s, diags
+}
+
+// waitForService polls the service until it is visible. Reads following a
+// write are served by a replicated store on the backend and may not find the
+// service for a short while.
+func (c *StartrailProviderClient) waitForService(ctx context.Context, environment string, name string) diag.Diagnostics {
+	delay := serviceVisibleDelay
+	for attempt := 1; ; attempt++ {
+		service, diags := c.getService(ctx, environment, name)
+		if diags.HasError() || service != nil {
+			return diags
+		}
+		if attempt == serviceVisibleAttempts {
+			break
+		}
+
+		tflog.Debug(ctx, "service is not visible yet, waiting", map[string]interface{}{
+			"attempt": attempt,
+			"wait":    delay.String(),
+		})
+		if !retrySleep(ctx, delay) {
+			diags.AddError("Service Not Visible", fmt.Sprintf("Gave up waiting for service %q to become visible: %s", name, ctx.Err()))
+			return diags
+		}
+		delay *= 2
+		if delay > retryMaxDelay {
+			delay = retryMaxDelay
+		}
+	}
+
+	var diags diag.Diagnostics
+	diags.AddError(
+		"Service Not Visible",
+		fmt.Sprintf("The service %q was created in environment %q but could not be read back after %d attempts.", name, environment, serviceVisibleAttempts),
+	)
+	return diags
+}
+
+// putService creates or replaces a service through the Startrail API and
+// returns the service as stored by the backend. Failures are reported as
+// failures of operation, e.g. "create service".
+func (c *StartrailProviderClient) putService(ctx context.Context, operation string, service bindings.Service) (*bindings.Service, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	// these are maintained by the backend and must not be sent back
+	service.UpdatedAt.Unset()
+	service.UpdatedBy.Unset()
+	service.UpdatedDate.Unset()
+
+	defer c.serviceCache.invalidate()
+
+	startrailResponse, execute, err := retryTransient(ctx, func(ctx context.Context) (*bindings.ServiceResponse, *http.Response, error) {
+		return c.Client.ServiceAPI.Create(ctx).Service(service).Execute()
+	})
+	if !handleStartrailResponse(&diags, operation, startrailResponse.GetDiagnostics(), execute, err) {
+		return nil, diags
+	}
+
+	s := startrailResponse.GetResponse()
+	return &s, diags
+}
+
+// addServiceNotFoundError reports that a service an attachment refers to does
+// not exist.
+func addServiceNotFoundError(diags *diag.Diagnostics, environment string, name string) {
+	diags.AddError(
+		"Service Not Found",
+		fmt.Sprintf("The service %q does not exist in environment %q. Create it before attaching configuration to it.", name, environment),
+	)
+}
+
+// checkImportTenant reports whether an import identifier refers to the tenant
+// the provider is configured for, and adds an error otherwise.
+func (c *StartrailProviderClient) checkImportTenant(diags *diag.Diagnostics, tenant string) bool {
+	if tenant != c.Tenant {
+		diags.AddError(
+			"Unexpected Import Tenant",
+			fmt.Sprintf("The service belongs to tenant %q but the provider is configured for tenant %q.", tenant, c.Tenant),
+		)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+	"github.com/srevinsaju/terraform-provider-startrail/internal/acctest"
+)
+
+// newEventuallyConsistentClient returns a client of the mock API, which does
+// not find services in the first hidden reads, like a backend which is
+// eventually consistent.
+func newEventuallyConsistentClient(t *testing.T, hidden int) (*StartrailProviderClient, *acctest.Server) {
+	t.Helper()
+
+	mock := acctest.NewServer()
+	t.Cleanup(mock.Close)
+	u, err := url.Parse(mock.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodGet && strings.HasPrefix(req.URL.Path, "/api/v1/service/") && hidden > 0 {
+			hidden--
+			return &http.Response{
+				StatusCode: http.StatusNotFound,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+
+	return &StartrailProviderClient{
+		Client: newClient(u, "test", "apiKey acctest", false, httpClient, nil),
+		Tenant: "default",
+	}, mock
+}
+
+func TestWaitForService(t *testing.T) {
+	waits := fakeRetryClock(t)
+	client, mock := newEventuallyConsistentClient(t, 3)
+	mock.PutService(bindings.Service{Tenant: "default", Environment: "development", Name: "hello-world", Access: []bindings.Access{}})
+
+	diags := client.waitForService(context.Background(), "development", "hello-world")
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if got := mock.Requests(http.MethodGet, "/api/v1/service/default/development/hello-world"); got != 1 {
+		t.Errorf("expected to read the visible service once, got %d reads", got)
+	}
+
+	// the delay doubles after every attempt
+	want := []time.Duration{serviceVisibleDelay, 2 * serviceVisibleDelay, 4 * serviceVisibleDelay}
+	if len(*waits) != len(want) {
+		t.Fatalf("expected waits %v, got %v", want, *waits)
+	}
+	for i := range want {
+		if (*waits)[i] != want[i] {
+			t.Errorf("expected waits %v, got %v", want, *waits)
+			break
+		}
+	}
+}
+
+func TestWaitForService_notVisible(t *testing.T) {
+	waits := fakeRetryClock(t)
+	client, mock := newEventuallyConsistentClient(t, serviceVisibleAttempts)
+	mock.PutService(bindings.Service{Tenant: "default", Environment: "development", Name: "hello-world", Access: []bindings.Access{}})
+
+	diags := client.waitForService(context.Background(), "development", "hello-world")
+	if !diags.HasError() {
+		t.Fatal("expected an error once the attempts are exhausted")
+	}
+	if got := diags.Errors()[0].Summary(); got != "Service Not Visible" {
+		t.Errorf("unexpected error: %s", got)
+	}
+	if got := diags.Errors()[0].Detail(); !strings.Contains(got, "after 10 attempts") {
+		t.Errorf("expected the detail to name the attempts, got: %s", got)
+	}
+	if len(*waits) != serviceVisibleAttempts-1 {
+		t.Errorf("expected to wait between all %d attempts, waited %d times", serviceVisibleAttempts, len(*waits))
+	}
+	var total time.Duration
+	for _, w := range *waits {
+		if w > retryMaxDelay {
+			t.Errorf("expected waits of at most %s, got %s", retryMaxDelay, w)
+		}
+		total += w
+	}
+	if total > time.Minute {
+		t.Errorf("expected to give up within a minute, waited %s", total)
+	}
+	if got := mock.Requests(http.MethodGet, "/api/v1/service/default/development/hello-world"); got != 0 {
+		t.Errorf("expected all reads to be hidden, got %d reads", got)
+	}
+}
+
+func TestWaitForService_cancelled(t *testing.T) {
+	fakeRetryClock(t)
+	client, _ := newEventuallyConsistentClient(t, serviceVisibleAttempts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	diags := client.waitForService(ctx, "development", "hello-world")
+	if !diags.HasError() || !strings.Contains(diags.Errors()[0].Detail(), "Gave up waiting") {
+		t.Errorf("expected to give up waiting, got %v", diags)
+	}
+}
@@ -6,8 +6,12 @@ package provider
 import (
 	"context"
 	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
@@ -22,6 +26,17 @@ type ServiceDataSource struct {
 	client *StartrailProviderClient
 }
 
+// ServiceDataSourceModel describes the data source data model.
+type ServiceDataSourceModel struct {
+	Id          types.String `tfsdk:"id"`
+	Name        types.String `tfsdk:"name"`
+	Environment types.String `tfsdk:"environment"`
+	Description types.String `tfsdk:"description"`
+	Remarks     types.String `tfsdk:"remarks"`
+	Disabled    types.Bool   `tfsdk:"disabled"`
+	Labels      types.Map    `tfsdk:"labels"`
+}
+
 func (d *ServiceDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_service"
 }
@@ -41,8 +56,27 @@ func (d *ServiceDataSource) Schema(ctx context.Context, req datasource.SchemaReq
 				Required:            true,
 			},
 			"environment": schema.StringAttribute{
-				MarkdownDescription: "Service environment",
-				Required:            true,
+				MarkdownDescription: "Service environment, defaults to the environment of the provider configuration",
+				Optional:            true,
+				Computed:            true,
+				Validators:          []validator.String{environmentValidator{}},
+			},
+			"description": schema.StringAttribute{
+				MarkdownDescription: "Service description",
+				Computed:            true,
+			},
+			"remarks": schema.StringAttribute{
+				MarkdownDescription: "Service remarks",
+				Computed:            true,
+			},
+			"disabled": schema.BoolAttribute{
+				MarkdownDescription: "Service disabled",
+				Computed:            true,
+			},
+			"labels": schema.MapAttribute{
+				MarkdownDescription: "Metadata labels of the service, without those matching the ignore_label_prefixes of the provider configuration",
+				Computed:            true,
+				ElementType:         types.StringType,
 			},
 		},
 	}
@@ -69,7 +103,15 @@ func (d *ServiceDataSource) Configure(ctx context.Context, req datasource.Config
 }
 
 func (d *ServiceDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
-	var data ServiceModel
+	if d.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	ctx, cancel := d.client.readContext(ctx)
+	defer cancel()
+
+	var data ServiceDataSourceModel
 
 	// Read Terraform configuration data into the model
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
@@ -82,26 +124,43 @@ func (d *ServiceDataSource) Read(ctx context.Context, req datasource.ReadRequest
 	if environment == "" {
 		environment = d.client.Environment
 	}
-
-	clientReq := d.client.Client.ServiceAPI.Get(ctx, d.client.Tenant, environment, data.Name.ValueString())
-	startrailResponse, execute, err := clientReq.Execute()
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete service, got error: %s", err))
+	if environment == "" {
+		addEnvironmentNotConfiguredError(&resp.Diagnostics, path.Root("environment"))
 		return
 	}
-	handleStartrailDiagnostics(startrailResponse.GetDiagnostics(), &resp.Diagnostics)
+
+	service, diags := d.client.readService(ctx, environment, data.Name.ValueString())
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-
-	if execute.StatusCode != 200 {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete service, got error: %s", execute.Body))
+	if service == nil {
+		resp.Diagnostics.AddError(
+			"Service Not Found",
+			fmt.Sprintf("The service %q does not exist in environment %q.", data.Name.ValueString(), environment),
+		)
 		return
 	}
 
-	data, diags := parseServiceResponse(startrailResponse)
+	labels := map[string]attr.Value{}
+	for k, v := range withoutIgnoredLabels(serviceLabels(service), d.client.ignoreLabelPrefixes) {
+		labels[k] = types.StringValue(v)
+	}
+	m, diags := types.MapValue(types.StringType, labels)
 	resp.Diagnostics.Append(diags...)
 
+	config := data
+	data = ServiceDataSourceModel{
+		Id:          types.StringValue(fmt.Sprintf("%s/%s/%s", service.GetTenant(), service.GetEnvironment(), service.GetName())),
+		Name:        types.StringValue(service.GetName()),
+		Environment: types.StringValue(service.GetEnvironment()),
+		Description: types.StringValue(service.GetDescription()),
+		Remarks:     types.StringValue(service.GetRemarks()),
+		Disabled:    types.BoolValue(service.GetDisabled()),
+		Labels:      m,
+	}
+	keepEnvironmentCase(config.Environment, &data.Environment)
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -0,0 +1,72 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+	"github.com/srevinsaju/terraform-provider-startrail/internal/acctest"
+)
+
+func putDataSourceService(mock *acctest.Server) {
+	service := bindings.Service{
+		Name:        "hello-world",
+		Environment: "development",
+		Tenant:      "default",
+		Description: "A service which tells hello world",
+		Access:      []bindings.Access{},
+	}
+	service.Metadata.Set(bindings.NewMetadata(map[string]string{"team": "platform", "startrail.io/managed-by": "startrail"}))
+	mock.PutService(service)
+}
+
+func TestServiceDataSource(t *testing.T) {
+	p, mock := newTestProvider(t)
+	typ := p.dataSourceType("startrail_service")
+	putDataSourceService(mock)
+
+	state := p.readDataSource("startrail_service", objectValue(typ, map[string]tftypes.Value{
+		"name":        stringValue("hello-world"),
+		"environment": stringValue("Development"),
+	}))
+	if got := stringAttribute(t, state, "id"); got != "default/development/hello-world" {
+		t.Errorf("unexpected id: %s", got)
+	}
+	if got := stringAttribute(t, state, "environment"); got != "Development" {
+		t.Errorf("expected environment to keep the configured case, got %s", got)
+	}
+	if got := stringAttribute(t, state, "description"); got != "A service which tells hello world" {
+		t.Errorf("unexpected description: %s", got)
+	}
+	var labels map[string]tftypes.Value
+	if err := attribute(t, state, "labels").As(&labels); err != nil || len(labels) != 2 {
+		t.Errorf("expected 2 labels, got %v (%v)", labels, err)
+	}
+}
+
+func TestServiceDataSource_providerEnvironment(t *testing.T) {
+	mock := acctest.NewServer()
+	t.Cleanup(mock.Close)
+	p := configureTestProvider(t, mock.URL, "acctest", nil, map[string]tftypes.Value{
+		"environment": stringValue("development"),
+		"ignore_label_prefixes": tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{
+			stringValue("startrail.io/"),
+		}),
+	})
+	typ := p.dataSourceType("startrail_service")
+	putDataSourceService(mock)
+
+	state := p.readDataSource("startrail_service", objectValue(typ, map[string]tftypes.Value{
+		"name": stringValue("hello-world"),
+	}))
+	if got := stringAttribute(t, state, "environment"); got != "development" {
+		t.Errorf("expected the environment of the provider, got %s", got)
+	}
+	var labels map[string]tftypes.Value
+	if err := attribute(t, state, "labels").As(&labels); err != nil || len(labels) != 1 || labels["team"].IsNull() {
+		t.Errorf("expected only the team label, got %v (%v)", labels, err)
+	}
+}
@@ -0,0 +1,320 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+	"strings"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &ServiceLabelResource{}
+var _ resource.ResourceWithImportState = &ServiceLabelResource{}
+
+func NewServiceLabelResource() resource.Resource {
+	return &ServiceLabelResource{}
+}
+
+// ServiceLabelResource manages a single metadata label of an existing service.
+type ServiceLabelResource struct {
+	client *StartrailProviderClient
+}
+
+// ServiceLabelModel describes the resource data model.
+type ServiceLabelModel struct {
+	Id          types.String `tfsdk:"id"`
+	Service     types.String `tfsdk:"service"`
+	Environment types.String `tfsdk:"environment"`
+	Key         types.String `tfsdk:"key"`
+	Value       types.String `tfsdk:"value"`
+}
+
+func (r *ServiceLabelResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_service_label"
+}
+
+func (r *ServiceLabelResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Manages a single metadata label on an existing service. " +
+			"Other labels on the service are left untouched. The service may be managed by a `startrail_service` resource, " +
+			"as long as the key is not declared in its `metadata` block as well.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Label identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"service": schema.StringAttribute{
+				MarkdownDescription: "Name of the service to label",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"environment": schema.StringAttribute{
+				MarkdownDescription: "Service environment",
+				Required:            true,
+				Validators:          []validator.String{environmentValidator{}},
+				PlanModifiers:       environmentPlanModifiers(),
+			},
+			"key": schema.StringAttribute{
+				MarkdownDescription: "Label key",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"value": schema.StringAttribute{
+				MarkdownDescription: "Label value",
+				Required:            true,
+			},
+		},
+	}
+}
+
+func (r *ServiceLabelResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*StartrailProviderClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *StartrailProviderClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+func (r *ServiceLabelResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	ctx, cancel := r.client.writeContext(ctx)
+	defer cancel()
+
+	var data ServiceLabelModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	environment := data.Environment.ValueString()
+	name := data.Service.ValueString()
+	defer r.client.lockService(environment, name)()
+
+	service, diags := r.client.getService(ctx, environment, name)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if service == nil {
+		addServiceNotFoundError(&resp.Diagnostics, environment, name)
+		return
+	}
+
+	labels := serviceLabels(service)
+	if _, ok := labels[data.Key.ValueString()]; ok {
+		resp.Diagnostics.AddError(
+			"Label Already Exists",
+			fmt.Sprintf("The service %q already has the label %q. Import it to manage it with Terraform.", name, data.Key.ValueString()),
+		)
+		return
+	}
+	labels[data.Key.ValueString()] = data.Value.ValueString()
+
+	_, diags = r.client.putService(ctx, "update service", *service)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Id = types.StringValue(fmt.Sprintf("%s/%s/%s/%s", r.client.Tenant, environment, name, data.Key.ValueString()))
+
+	tflog.Trace(ctx, "created a service label")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ServiceLabelResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Keep the prior state until the provider configuration is known.
+	if r.client == nil {
+		return
+	}
+
+	ctx, cancel := r.client.readContext(ctx)
+	defer cancel()
+
+	var data ServiceLabelModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	service, diags := r.client.getService(ctx, data.Environment.ValueString(), data.Service.ValueString())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if service == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	value, ok := serviceLabels(service)[data.Key.ValueString()]
+	if !ok {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	data.Value = types.StringValue(value)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ServiceLabelResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	ctx, cancel := r.client.writeContext(ctx)
+	defer cancel()
+
+	var data ServiceLabelModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	environment := data.Environment.ValueString()
+	name := data.Service.ValueString()
+	defer r.client.lockService(environment, name)()
+
+	service, diags := r.client.getService(ctx, environment, name)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if service == nil {
+		addServiceNotFoundError(&resp.Diagnostics, environment, name)
+		return
+	}
+
+	serviceLabels(service)[data.Key.ValueString()] = data.Value.ValueString()
+
+	_, diags = r.client.putService(ctx, "update service", *service)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ServiceLabelResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	ctx, cancel := r.client.writeContext(ctx)
+	defer cancel()
+
+	var data ServiceLabelModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	environment := data.Environment.ValueString()
+	name := data.Service.ValueString()
+	defer r.client.lockService(environment, name)()
+
+	service, diags := r.client.getService(ctx, environment, name)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || service == nil {
+		return
+	}
+
+	labels := serviceLabels(service)
+	if _, ok := labels[data.Key.ValueString()]; !ok {
+		return
+	}
+	delete(labels, data.Key.ValueString())
+
+	_, diags = r.client.putService(ctx, "update service", *service)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *ServiceLabelResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	parts := strings.SplitN(req.ID, "/", 4)
+	if len(parts) != 4 || parts[0] == "" || parts[1] == "" || parts[2] == "" || parts[3] == "" {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: tenant/environment/service/key. Got: %q", req.ID),
+		)
+		return
+	}
+	if !r.client.checkImportTenant(&resp.Diagnostics, parts[0]) {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("environment"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("service"), parts[2])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("key"), parts[3])...)
+}
+
+// serviceLabels returns the metadata labels of the service, initializing the
+// metadata of the service when it has none so that the returned map can be
+// modified in place.
+func serviceLabels(service *bindings.Service) map[string]string {
+	m := service.Metadata.Get()
+	if m == nil {
+		m = bindings.NewMetadata(map[string]string{})
+		service.Metadata.Set(m)
+	}
+	if m.Labels == nil {
+		m.Labels = map[string]string{}
+	}
+	return m.Labels
+}
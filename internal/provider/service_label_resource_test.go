@@ -0,0 +1,112 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+)
+
+func TestServiceLabelResource(t *testing.T) {
+	p, mock := newTestProvider(t)
+	typ := p.resourceType("startrail_service_label")
+
+	mock.PutService(bindings.Service{
+		Name:        "hello-world",
+		Environment: "development",
+		Tenant:      "default",
+		Access:      []bindings.Access{},
+		Metadata:    *bindings.NewNullableMetadata(bindings.NewMetadata(map[string]string{"owned-elsewhere": "yes"})),
+	})
+	config := func(value string) tftypes.Value {
+		return objectValue(typ, map[string]tftypes.Value{
+			"service":     stringValue("hello-world"),
+			"environment": stringValue("development"),
+			"key":         stringValue("team"),
+			"value":       stringValue(value),
+		})
+	}
+	labels := func() map[string]string {
+		service, _ := mock.Service("default", "development", "hello-world")
+		return serviceLabels(&service)
+	}
+
+	// create
+	state := p.apply("startrail_service_label", tftypes.NewValue(typ, nil), config("platform"))
+	if got := stringAttribute(t, state, "id"); got != "default/development/hello-world/team" {
+		t.Errorf("unexpected id: %s", got)
+	}
+	if l := labels(); len(l) != 2 || l["team"] != "platform" {
+		t.Fatalf("expected 2 labels with team=platform, got %+v", l)
+	}
+
+	// read
+	state = p.read("startrail_service_label", state)
+	if got := stringAttribute(t, state, "value"); got != "platform" {
+		t.Errorf("unexpected value after read: %s", got)
+	}
+
+	// update
+	state = p.apply("startrail_service_label", state, config("observability"))
+	if l := labels(); len(l) != 2 || l["team"] != "observability" {
+		t.Errorf("expected team=observability, got %+v", l)
+	}
+
+	// import
+	imported := p.importState("startrail_service_label", stringAttribute(t, state, "id"))
+	if got := stringAttribute(t, imported, "value"); got != "observability" {
+		t.Errorf("unexpected imported value: %s", got)
+	}
+	if got := p.importStateError("startrail_service_label", "other/development/hello-world/team"); got != "Unexpected Import Tenant" {
+		t.Errorf("expected the import of another tenant to fail, got %q", got)
+	}
+
+	// delete
+	p.apply("startrail_service_label", state, tftypes.NewValue(typ, nil))
+	if l := labels(); len(l) != 1 || l["owned-elsewhere"] != "yes" {
+		t.Errorf("expected only the unmanaged label to remain, got %+v", l)
+	}
+
+	// read of a removed label
+	if got := p.read("startrail_service_label", state); !got.IsNull() {
+		t.Errorf("expected state to be removed, got %s", got)
+	}
+}
+
+func TestServiceLabelResource_managedService(t *testing.T) {
+	p, mock := newTestProvider(t)
+	serviceType := p.resourceType("startrail_service")
+	typ := p.resourceType("startrail_service_label")
+
+	service := p.apply("startrail_service", tftypes.NewValue(serviceType, nil), testServiceConfig(serviceType, "A service which tells hello world"))
+	label := p.apply("startrail_service_label", tftypes.NewValue(typ, nil), objectValue(typ, map[string]tftypes.Value{
+		"service":     stringValue("hello-world"),
+		"environment": stringValue("development"),
+		"key":         stringValue("owner"),
+		"value":       stringValue("alice"),
+	}))
+
+	// the label is neither read into the state of the service, nor removed
+	// when the service is updated
+	service = p.read("startrail_service", service)
+	var metadata map[string]tftypes.Value
+	var labels map[string]tftypes.Value
+	if err := attribute(t, service, "metadata").As(&metadata); err != nil {
+		t.Fatal(err)
+	}
+	if err := metadata["labels"].As(&labels); err != nil || len(labels) != 1 {
+		t.Errorf("expected 1 label in the service state, got %v (%v)", labels, err)
+	}
+	p.apply("startrail_service", service, testServiceConfig(serviceType, "A service which tells hello"))
+
+	s, _ := mock.Service("default", "development", "hello-world")
+	if l := serviceLabels(&s); len(l) != 2 || l["team"] != "platform" || l["owner"] != "alice" {
+		t.Fatalf("expected labels team and owner after updating the service, got %+v", l)
+	}
+	if got := p.read("startrail_service_label", label); got.IsNull() {
+		t.Error("expected the label to survive the update of the service")
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &ServiceLoggingAttachmentResource{}
+var _ resource.ResourceWithImportState = &ServiceLoggingAttachmentResource{}
+
+func NewServiceLoggingAttachmentResource() resource.Resource {
+	return &ServiceLoggingAttachmentResource{
+		serviceAttachment: serviceAttachment[bindings.Logging]{
+			kind: "logging configuration",
+			entries: func(service *bindings.Service) *map[string]bindings.Logging {
+				return &service.Logging
+			},
+			newEntry: func(labels map[string]string) bindings.Logging {
+				return bindings.Logging{Labels: labels}
+			},
+			entryLabels: func(entry bindings.Logging) map[string]string {
+				return entry.Labels
+			},
+		},
+	}
+}
+
+// ServiceLoggingAttachmentResource attaches a logging configuration to an
+// existing service.
+type ServiceLoggingAttachmentResource struct {
+	serviceAttachment[bindings.Logging]
+}
+
+func (r *ServiceLoggingAttachmentResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_service_logging_attachment"
+}
+
+func (r *ServiceLoggingAttachmentResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = serviceAttachmentSchema(
+		"Attaches a logging configuration to an existing service. "+
+			"The service may be managed by a `startrail_service` resource, as long as the source is not declared in one of its `logging` blocks as well.",
+		"logging configuration",
+	)
+}
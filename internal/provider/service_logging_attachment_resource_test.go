@@ -0,0 +1,26 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+)
+
+func serviceLogging(service bindings.Service) map[string]map[string]string {
+	entries := map[string]map[string]string{}
+	for k, l := range service.Logging {
+		entries[k] = l.Labels
+	}
+	return entries
+}
+
+func TestServiceLoggingAttachmentResource(t *testing.T) {
+	testServiceAttachment(t, "startrail_service_logging_attachment", serviceLogging)
+}
+
+func TestServiceLoggingAttachmentResource_managedService(t *testing.T) {
+	testServiceAttachment_managedService(t, "startrail_service_logging_attachment", "logging", serviceLogging)
+}
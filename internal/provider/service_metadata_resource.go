@@ -0,0 +1,331 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+	"strings"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &ServiceMetadataResource{}
+var _ resource.ResourceWithImportState = &ServiceMetadataResource{}
+
+func NewServiceMetadataResource() resource.Resource {
+	return &ServiceMetadataResource{}
+}
+
+// ServiceMetadataResource authoritatively manages the metadata of an existing
+// service.
+type ServiceMetadataResource struct {
+	client *StartrailProviderClient
+}
+
+// ServiceMetadataModel describes the resource data model.
+type ServiceMetadataModel struct {
+	Id          types.String `tfsdk:"id"`
+	Service     types.String `tfsdk:"service"`
+	Environment types.String `tfsdk:"environment"`
+	Labels      types.Map    `tfsdk:"labels"`
+
+	IgnoreLabelPrefixes types.List `tfsdk:"ignore_label_prefixes"`
+}
+
+func (r *ServiceMetadataResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_service_metadata"
+}
+
+func (r *ServiceMetadataResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Authoritatively manages the metadata of an existing service. " +
+			"Labels not declared here are removed from the service, do not combine with `startrail_service_label` " +
+			"or the `metadata` block of `startrail_service` for the same service. " +
+			"The service may be managed by a `startrail_service` resource without a `metadata` block.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Service identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"service": schema.StringAttribute{
+				MarkdownDescription: "Name of the service to manage the metadata of",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"environment": schema.StringAttribute{
+				MarkdownDescription: "Service environment",
+				Required:            true,
+				Validators:          []validator.String{environmentValidator{}},
+				PlanModifiers:       environmentPlanModifiers(),
+			},
+			"labels": schema.MapAttribute{
+				MarkdownDescription: "The complete set of labels of the service",
+				Required:            true,
+				ElementType:         types.StringType,
+			},
+			"ignore_label_prefixes": schema.ListAttribute{
+				MarkdownDescription: "Prefixes of labels managed outside of Terraform, in addition to those of the provider configuration. " +
+					"Matching labels are kept on the service.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (r *ServiceMetadataResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*StartrailProviderClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *StartrailProviderClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+func (r *ServiceMetadataResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	ctx, cancel := r.client.writeContext(ctx)
+	defer cancel()
+
+	var data ServiceMetadataModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data, diags := r.put(ctx, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Trace(ctx, "created service metadata")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ServiceMetadataResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Keep the prior state until the provider configuration is known.
+	if r.client == nil {
+		return
+	}
+
+	ctx, cancel := r.client.readContext(ctx)
+	defer cancel()
+
+	var data ServiceMetadataModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	service, diags := r.client.getService(ctx, data.Environment.ValueString(), data.Service.ValueString())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if service == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	prefixes, diags := r.client.labelPrefixes(ctx, data.IgnoreLabelPrefixes)
+	resp.Diagnostics.Append(diags...)
+
+	labels := map[string]attr.Value{}
+	for k, v := range withoutIgnoredLabels(serviceLabels(service), prefixes) {
+		labels[k] = types.StringValue(v)
+	}
+	m, d := types.MapValue(types.StringType, labels)
+	resp.Diagnostics.Append(d...)
+	data.Labels = m
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ServiceMetadataResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	ctx, cancel := r.client.writeContext(ctx)
+	defer cancel()
+
+	var data ServiceMetadataModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data, diags := r.put(ctx, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ServiceMetadataResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	ctx, cancel := r.client.writeContext(ctx)
+	defer cancel()
+
+	var data ServiceMetadataModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	environment := data.Environment.ValueString()
+	name := data.Service.ValueString()
+	defer r.client.lockService(environment, name)()
+
+	service, diags := r.client.getService(ctx, environment, name)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || service == nil {
+		return
+	}
+
+	prefixes, diags := r.client.labelPrefixes(ctx, data.IgnoreLabelPrefixes)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// labels managed outside of Terraform outlive the resource
+	labels := map[string]string{}
+	for k, v := range serviceLabels(service) {
+		if ignoredLabel(k, prefixes) {
+			labels[k] = v
+		}
+	}
+	service.Metadata.Set(bindings.NewMetadata(labels))
+
+	_, diags = r.client.putService(ctx, "update service", *service)
+	resp.Diagnostics.Append(diags...)
+}
+
+// put replaces the metadata of the service with the one described by data.
+func (r *ServiceMetadataResource) put(ctx context.Context, data ServiceMetadataModel) (ServiceMetadataModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	environment := data.Environment.ValueString()
+	name := data.Service.ValueString()
+	defer r.client.lockService(environment, name)()
+
+	service, d := r.client.getService(ctx, environment, name)
+	diags.Append(d...)
+	if diags.HasError() {
+		return data, diags
+	}
+	if service == nil {
+		addServiceNotFoundError(&diags, environment, name)
+		return data, diags
+	}
+
+	labels := map[string]string{}
+	diags.Append(data.Labels.ElementsAs(ctx, &labels, true)...)
+	prefixes, d := r.client.labelPrefixes(ctx, data.IgnoreLabelPrefixes)
+	diags.Append(d...)
+	if diags.HasError() {
+		return data, diags
+	}
+	for k := range labels {
+		if ignoredLabel(k, prefixes) {
+			diags.AddAttributeError(
+				path.Root("labels").AtMapKey(k),
+				"Ignored Label Configured",
+				fmt.Sprintf("The label %q matches ignore_label_prefixes and is managed outside of Terraform. Remove it from configuration, or from the ignored prefixes.", k),
+			)
+		}
+	}
+	if diags.HasError() {
+		return data, diags
+	}
+	for k, v := range serviceLabels(service) {
+		if ignoredLabel(k, prefixes) {
+			labels[k] = v
+		}
+	}
+	service.Metadata.Set(bindings.NewMetadata(labels))
+
+	_, d = r.client.putService(ctx, "update service", *service)
+	diags.Append(d...)
+
+	data.Id = types.StringValue(fmt.Sprintf("%s/%s/%s", r.client.Tenant, environment, name))
+	return data, diags
+}
+
+func (r *ServiceMetadataResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	parts := strings.Split(req.ID, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: tenant/environment/service. Got: %q", req.ID),
+		)
+		return
+	}
+	if !r.client.checkImportTenant(&resp.Diagnostics, parts[0]) {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("environment"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("service"), parts[2])...)
+}
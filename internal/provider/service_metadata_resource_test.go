@@ -0,0 +1,127 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+)
+
+func testServiceMetadataConfig(typ tftypes.Object, labels map[string]string) tftypes.Value {
+	vals := map[string]tftypes.Value{}
+	for k, v := range labels {
+		vals[k] = stringValue(v)
+	}
+	return objectValue(typ, map[string]tftypes.Value{
+		"service":     stringValue("hello-world"),
+		"environment": stringValue("development"),
+		"labels":      tftypes.NewValue(typ.AttributeTypes["labels"], vals),
+	})
+}
+
+func TestServiceMetadataResource(t *testing.T) {
+	p, mock := newTestProvider(t)
+	typ := p.resourceType("startrail_service_metadata")
+
+	mock.PutService(bindings.Service{
+		Name:        "hello-world",
+		Environment: "development",
+		Tenant:      "default",
+		Access:      []bindings.Access{},
+		Metadata:    *bindings.NewNullableMetadata(bindings.NewMetadata(map[string]string{"stale": "yes"})),
+	})
+	labels := func() map[string]string {
+		service, _ := mock.Service("default", "development", "hello-world")
+		return serviceLabels(&service)
+	}
+
+	// create, the metadata is managed authoritatively
+	state := p.apply("startrail_service_metadata", tftypes.NewValue(typ, nil), testServiceMetadataConfig(typ, map[string]string{"team": "platform"}))
+	if got := stringAttribute(t, state, "id"); got != "default/development/hello-world" {
+		t.Errorf("unexpected id: %s", got)
+	}
+	if l := labels(); len(l) != 1 || l["team"] != "platform" {
+		t.Fatalf("expected only team=platform, got %+v", l)
+	}
+
+	// read
+	state = p.read("startrail_service_metadata", state)
+	var vals map[string]tftypes.Value
+	if err := attribute(t, state, "labels").As(&vals); err != nil || len(vals) != 1 {
+		t.Errorf("expected 1 label after read, got %v (%v)", vals, err)
+	}
+
+	// update
+	state = p.apply("startrail_service_metadata", state, testServiceMetadataConfig(typ, map[string]string{"team": "observability", "tier": "web"}))
+	if l := labels(); len(l) != 2 || l["team"] != "observability" || l["tier"] != "web" {
+		t.Errorf("expected team=observability and tier=web, got %+v", l)
+	}
+
+	// import
+	imported := p.importState("startrail_service_metadata", stringAttribute(t, state, "id"))
+	if err := attribute(t, imported, "labels").As(&vals); err != nil || len(vals) != 2 {
+		t.Errorf("expected 2 imported labels, got %v (%v)", vals, err)
+	}
+	if got := p.importStateError("startrail_service_metadata", "other/development/hello-world"); got != "Unexpected Import Tenant" {
+		t.Errorf("expected the import of another tenant to fail, got %q", got)
+	}
+
+	// delete
+	p.apply("startrail_service_metadata", state, tftypes.NewValue(typ, nil))
+	if l := labels(); len(l) != 0 {
+		t.Errorf("expected no labels to remain, got %+v", l)
+	}
+
+	// delete keeps the labels injected by the backend
+	var ignoring map[string]tftypes.Value
+	_ = testServiceMetadataConfig(typ, map[string]string{"team": "platform"}).As(&ignoring)
+	ignoring["ignore_label_prefixes"] = tftypes.NewValue(typ.AttributeTypes["ignore_label_prefixes"], []tftypes.Value{
+		stringValue("startrail.io/"),
+	})
+	state = p.apply("startrail_service_metadata", tftypes.NewValue(typ, nil), tftypes.NewValue(typ, ignoring))
+	service, _ := mock.Service("default", "development", "hello-world")
+	serviceLabels(&service)["startrail.io/managed-by"] = "startrail"
+	mock.PutService(service)
+
+	p.apply("startrail_service_metadata", state, tftypes.NewValue(typ, nil))
+	if l := labels(); len(l) != 1 || l["startrail.io/managed-by"] != "startrail" {
+		t.Errorf("expected only the system label to remain, got %+v", l)
+	}
+}
+
+func TestServiceMetadataResource_managedService(t *testing.T) {
+	p, mock := newTestProvider(t)
+	serviceType := p.resourceType("startrail_service")
+	typ := p.resourceType("startrail_service_metadata")
+
+	// a service without a metadata block leaves the labels to
+	// startrail_service_metadata
+	serviceConfig := func(description string) tftypes.Value {
+		var vals map[string]tftypes.Value
+		_ = testServiceConfig(serviceType, description).As(&vals)
+		vals["metadata"] = tftypes.NewValue(serviceType.AttributeTypes["metadata"], nil)
+		return tftypes.NewValue(serviceType, vals)
+	}
+
+	service := p.apply("startrail_service", tftypes.NewValue(serviceType, nil), serviceConfig("A service which tells hello world"))
+	metadata := p.apply("startrail_service_metadata", tftypes.NewValue(typ, nil), testServiceMetadataConfig(typ, map[string]string{"team": "platform"}))
+
+	service = p.read("startrail_service", service)
+	if got := attribute(t, service, "metadata"); !got.IsNull() {
+		t.Errorf("expected no metadata in the service state, got %s", got)
+	}
+	p.apply("startrail_service", service, serviceConfig("A service which tells hello"))
+
+	s, _ := mock.Service("default", "development", "hello-world")
+	if l := serviceLabels(&s); len(l) != 1 || l["team"] != "platform" {
+		t.Fatalf("expected team=platform after updating the service, got %+v", l)
+	}
+	metadata = p.read("startrail_service_metadata", metadata)
+	var vals map[string]tftypes.Value
+	if err := attribute(t, metadata, "labels").As(&vals); err != nil || len(vals) != 1 {
+		t.Errorf("expected the labels to survive the update of the service, got %v (%v)", vals, err)
+	}
+}
@@ -11,7 +11,10 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	bindings "github.com/srevinsaju/startrail-go-sdk"
+	"net/http"
+	"reflect"
 	"regexp"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -25,6 +28,7 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &ServiceResource{}
 var _ resource.ResourceWithImportState = &ServiceResource{}
+var _ resource.ResourceWithModifyPlan = &ServiceResource{}
 
 func NewServiceResource() resource.Resource {
 	return &ServiceResource{}
@@ -62,7 +66,9 @@ func (r *ServiceResource) Metadata(ctx context.Context, req resource.MetadataReq
 func (r *ServiceResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		// This description is used by the documentation generator and the language server.
-		MarkdownDescription: "Service resource",
+		MarkdownDescription: "Service resource. Access entries, logging configurations, sources and metadata labels of the service which are not declared in " +
+			"`access`, `logging`, `source` and `metadata` blocks, such as those of `startrail_service_access`, `startrail_service_logging_attachment`, " +
+			"`startrail_service_source_attachment` and `startrail_service_label` resources, are left untouched.",
 		Blocks: map[string]schema.Block{
 			"access": schema.ListNestedBlock{
 				NestedObject: schema.NestedBlockObject{
@@ -156,26 +162,37 @@ func (r *ServiceResource) Schema(ctx context.Context, req resource.SchemaRequest
 				MarkdownDescription: "Service description",
 				Optional:            true,
 				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"disabled": schema.BoolAttribute{
 				MarkdownDescription: "Service disabled",
 				Computed:            true,
 			},
 			"environment": schema.StringAttribute{
-				MarkdownDescription: "Service environment",
-				Required:            true,
+				MarkdownDescription: "Service environment, defaults to the environment of the provider configuration",
+				Optional:            true,
+				Computed:            true,
 				Validators: []validator.String{
+					environmentValidator{},
 					stringvalidator.RegexMatches(
-						regexp.MustCompile(`^[a-z0-9-]+$`), "Environment must be lowercase alphanumeric with dashes"),
-				},
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+						regexp.MustCompile(`^[A-Za-z0-9-]+$`), "Environment must be alphanumeric with dashes"),
 				},
+				PlanModifiers: environmentPlanModifiers(),
+			},
+			"ignore_label_prefixes": schema.ListAttribute{
+				MarkdownDescription: "Prefixes of metadata labels managed outside of Terraform, in addition to those of the provider configuration",
+				Optional:            true,
+				ElementType:         types.StringType,
 			},
 			"remarks": schema.StringAttribute{
 				MarkdownDescription: "Service remarks",
 				Optional:            true,
 				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 		},
 	}
@@ -201,7 +218,50 @@ func (r *ServiceResource) Configure(ctx context.Context, req resource.ConfigureR
 	r.client = client
 }
 
+// ModifyPlan plans the environment of the provider configuration for
+// resources which do not set one.
+func (r *ServiceResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to plan when the resource is destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var environment types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("environment"), &environment)...)
+	if resp.Diagnostics.HasError() || !environment.IsNull() {
+		return
+	}
+
+	// the provider configuration is not known yet
+	if r.client == nil {
+		return
+	}
+	if r.client.Environment == "" {
+		addEnvironmentNotConfiguredError(&resp.Diagnostics, path.Root("environment"))
+		return
+	}
+
+	planned := types.StringValue(r.client.Environment)
+	if !req.State.Raw.IsNull() {
+		var prior types.String
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("environment"), &prior)...)
+		if !strings.EqualFold(prior.ValueString(), planned.ValueString()) {
+			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("environment"))
+		}
+		keepEnvironmentCase(prior, &planned)
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("environment"), planned)...)
+}
+
 func (r *ServiceResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	ctx, cancel := r.client.writeContext(ctx)
+	defer cancel()
+
 	var data ServiceModel
 
 	// Read Terraform plan data into the model
@@ -210,8 +270,16 @@ func (r *ServiceResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
-	data, diags := r.post(ctx, data)
+	data, diags := r.post(ctx, ServiceModel{}, data)
 	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.client.waitForService(ctx, data.Environment.ValueString(), data.Name.ValueString())...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	// Write logs using the tflog package
 	// Documentation: https://terraform.io/plugin/log
@@ -223,6 +291,14 @@ func (r *ServiceResource) Create(ctx context.Context, req resource.CreateRequest
 
 func (r *ServiceResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 
+	// Keep the prior state until the provider configuration is known.
+	if r.client == nil {
+		return
+	}
+
+	ctx, cancel := r.client.readContext(ctx)
+	defer cancel()
+
 	var data ServiceModel
 
 	// Read Terraform prior state data into the model
@@ -232,44 +308,132 @@ func (r *ServiceResource) Read(ctx context.Context, req resource.ReadRequest, re
 		return
 	}
 
+	// state written by an import only carries the identifying attributes,
+	// disabled is always set once the service has been read
+	prior := data
+	imported := data.Disabled.IsNull()
+
+	// imported resources only carry the identifier until the first read
+	if data.Name.IsNull() || data.Environment.IsNull() {
+		parts := strings.Split(data.Id.ValueString(), "/")
+		if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+			resp.Diagnostics.AddError(
+				"Unexpected Service Identifier",
+				fmt.Sprintf("Expected service identifier with format: tenant/environment/name. Got: %q", data.Id.ValueString()),
+			)
+			return
+		}
+		data.Environment = types.StringValue(parts[1])
+		data.Name = types.StringValue(parts[2])
+	}
+
 	environment := data.Environment.ValueString()
 	if environment == "" {
 		environment = r.client.Environment
 	}
 
-	clientReq := r.client.Client.ServiceAPI.Get(ctx, r.client.Tenant, environment, data.Name.ValueString())
-	startrailResponse, execute, err := clientReq.Execute()
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete service, got error: %s", err))
-		return
-	}
-	handleStartrailDiagnostics(startrailResponse.GetDiagnostics(), &resp.Diagnostics)
+	service, diags := r.client.readService(ctx, environment, data.Name.ValueString())
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-
-	if execute.StatusCode != 200 {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete service, got error: %s", execute.Body))
+	if service == nil {
+		resp.State.RemoveResource(ctx)
 		return
 	}
 
-	data, diags := parseServiceResponse(startrailResponse)
+	data, diags = parseService(*service)
 	resp.Diagnostics.Append(diags...)
+	// imported services are read as a whole, so that the generated
+	// configuration describes them completely
+	if !imported {
+		onlyManaged(&data, managedKeys(prior))
+	}
+	resp.Diagnostics.Append(r.ignoreLabels(ctx, prior, &data)...)
+	keepEmptyLabels(prior, &data)
+	keepEnvironmentCase(prior.Environment, &data.Environment)
+
+	if !imported {
+		reportServiceDrift(ctx, &resp.Diagnostics, prior, data, *service)
+	}
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+// reportServiceDrift warns about the fields of the service which changed
+// since they were last stored in state, along with who changed them last, so
+// that plan reviewers can tell out-of-band changes apart.
+func reportServiceDrift(ctx context.Context, diags *diag.Diagnostics, prior ServiceModel, current ServiceModel, service bindings.Service) {
+	var drifted []string
+	// unset values are compared as the zero values the backend returns for
+	// them, so that a refresh without changes reports no drift
+	if prior.Description.ValueString() != current.Description.ValueString() {
+		drifted = append(drifted, "description")
+	}
+	if prior.Remarks.ValueString() != current.Remarks.ValueString() {
+		drifted = append(drifted, "remarks")
+	}
+	if prior.Disabled.ValueBool() != current.Disabled.ValueBool() {
+		drifted = append(drifted, "disabled")
+	}
+	if !reflect.DeepEqual(accessEntries(prior.Access), accessEntries(current.Access)) {
+		drifted = append(drifted, "access")
+	}
+	if !reflect.DeepEqual(loggingEntries(prior.Logging), loggingEntries(current.Logging)) {
+		drifted = append(drifted, "logging")
+	}
+	if !reflect.DeepEqual(sourceEntries(prior.Sources), sourceEntries(current.Sources)) {
+		drifted = append(drifted, "source")
+	}
+	if !reflect.DeepEqual(metadataLabels(prior.Metadata), metadataLabels(current.Metadata)) {
+		drifted = append(drifted, "metadata")
+	}
+	if len(drifted) == 0 {
+		return
+	}
+
+	updatedBy := service.GetUpdatedBy()
+	if updatedBy == "" {
+		updatedBy = "unknown"
+	}
+	updatedAt := service.GetUpdatedDate()
+	if updatedAt == "" {
+		updatedAt = "unknown"
+	}
+
+	tflog.Warn(ctx, "service changed outside of Terraform", map[string]interface{}{
+		"service":    service.GetName(),
+		"fields":     drifted,
+		"updated_by": updatedBy,
+		"updated_at": updatedAt,
+	})
+	diags.AddWarning(
+		"Service Changed Outside of Terraform",
+		fmt.Sprintf("The following fields of service %q changed since the last refresh: %s.\n\nLast updated by: %s\nLast updated at: %s",
+			service.GetName(), strings.Join(drifted, ", "), updatedBy, updatedAt),
+	)
+}
+
 func (r *ServiceResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var data ServiceModel
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
 
-	// Read Terraform plan data into the model
+	ctx, cancel := r.client.writeContext(ctx)
+	defer cancel()
+
+	var data, prior ServiceModel
+
+	// Read Terraform plan data and prior state into the models
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	data, diags := r.post(ctx, data)
+	data, diags := r.post(ctx, prior, data)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -279,7 +443,58 @@ func (r *ServiceResource) Update(ctx context.Context, req resource.UpdateRequest
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
-func (r *ServiceResource) post(ctx context.Context, data ServiceModel) (ServiceModel, diag.Diagnostics) {
+// accessEntries returns the auth and internal flags of access entries by
+// endpoint, with unset flags as false.
+func accessEntries(access []ServiceResourceModelAccess) map[string][2]bool {
+	entries := map[string][2]bool{}
+	for _, a := range access {
+		entries[a.Endpoint.ValueString()] = [2]bool{a.Auth.ValueBool(), a.Internal.ValueBool()}
+	}
+	return entries
+}
+
+// loggingEntries returns the labels of logging configurations by source.
+func loggingEntries(logging []ServiceResourceModelLogging) map[string]map[string]string {
+	entries := map[string]map[string]string{}
+	for _, l := range logging {
+		entries[l.Source.ValueString()] = labelValues(l.Labels)
+	}
+	return entries
+}
+
+// sourceEntries returns the labels of sources by source.
+func sourceEntries(sources []ServiceResourceM0delSource) map[string]map[string]string {
+	entries := map[string]map[string]string{}
+	for _, s := range sources {
+		entries[s.Source.ValueString()] = labelValues(s.Labels)
+	}
+	return entries
+}
+
+// metadataLabels returns the labels of metadata, with no metadata and unset
+// labels as no labels.
+func metadataLabels(metadata *ServiceResourceModelMetadata) map[string]string {
+	if metadata == nil {
+		return map[string]string{}
+	}
+	return labelValues(metadata.Labels)
+}
+
+// labelValues returns the values of a label map, with null and unknown maps
+// as no labels.
+func labelValues(labels types.Map) map[string]string {
+	values := map[string]string{}
+	for k, v := range labels.Elements() {
+		if s, ok := v.(types.String); ok {
+			values[k] = s.ValueString()
+		}
+	}
+	return values
+}
+
+// post creates or replaces the service described by data. prior is the state
+// before the change, or empty when the service is created.
+func (r *ServiceResource) post(ctx context.Context, prior ServiceModel, data ServiceModel) (ServiceModel, diag.Diagnostics) {
 
 	var diags diag.Diagnostics
 
@@ -290,7 +505,69 @@ func (r *ServiceResource) post(ctx context.Context, data ServiceModel) (ServiceM
 	}
 	tenant := r.client.Tenant
 
+	prefixes, d := r.client.labelPrefixes(ctx, data.IgnoreLabelPrefixes)
+	diags.Append(d...)
+	if diags.HasError() {
+		return ServiceModel{}, diags
+	}
+
+	service, d := buildService(ctx, data, tenant, environment, prefixes)
+	diags.Append(d...)
+	if diags.HasError() {
+		return ServiceModel{}, diags
+	}
+
+	// the service is replaced as a whole, keep the entries and labels owned
+	// by attachment resources or managed outside of Terraform
+	defer r.client.lockService(environment, data.Name.ValueString())()
+	current, d := r.client.getService(ctx, environment, data.Name.ValueString())
+	diags.Append(d...)
+	if diags.HasError() {
+		return ServiceModel{}, diags
+	}
+	if current != nil {
+		keepUnmanaged(&service, *current, managedKeys(prior, data))
+	}
+
+	operation := "update service"
+	if prior.Id.IsNull() {
+		operation = "create service"
+	}
+	stored, d := r.client.putService(ctx, operation, service)
+	diags.Append(d...)
+	if diags.HasError() {
+		return ServiceModel{}, diags
+	}
+
+	result, d := parseService(*stored)
+	diags.Append(d...)
+	onlyManaged(&result, managedKeys(data))
+	diags.Append(r.ignoreLabels(ctx, data, &result)...)
+	keepEmptyLabels(data, &result)
+	keepEnvironmentCase(data.Environment, &result.Environment)
+	return result, diags
+}
+
+// buildService returns the service described by data, as sent to the
+// Startrail API. Labels matching one of prefixes cannot be configured.
+func buildService(ctx context.Context, data ServiceModel, tenant string, environment string, prefixes []string) (bindings.Service, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
 	metadata := bindings.NullableMetadata{}
+	if data.Metadata != nil && !data.Metadata.Labels.IsUnknown() {
+		m := bindings.NewMetadata(map[string]string{})
+		data.Metadata.Labels.ElementsAs(ctx, &m.Labels, true)
+		for k := range m.Labels {
+			if ignoredLabel(k, prefixes) {
+				diags.AddAttributeError(
+					path.Root("metadata").AtName("labels").AtMapKey(k),
+					"Ignored Label Configured",
+					fmt.Sprintf("The label %q matches ignore_label_prefixes and is managed outside of Terraform. Remove it from configuration, or from the ignored prefixes.", k),
+				)
+			}
+		}
+		metadata.Set(m)
+	}
 	logging := map[string]bindings.Logging{}
 	sources := map[string]bindings.Source{}
 	access := []bindings.Access{}
@@ -313,11 +590,11 @@ func (r *ServiceResource) post(ctx context.Context, data ServiceModel) (ServiceM
 		sources[s.Source.ValueString()] = b
 	}
 
-	service := bindings.Service{
+	return bindings.Service{
 		Name:        data.Name.ValueString(),
 		Description: data.Description.ValueString(),
 		Remarks:     data.Remarks.ValueString(),
-		Environment: environment,
+		Environment: normalizeEnvironment(environment),
 		Tenant:      tenant,
 
 		Disabled: data.Disabled.ValueBoolPointer(),
@@ -325,35 +602,173 @@ func (r *ServiceResource) post(ctx context.Context, data ServiceModel) (ServiceM
 		Metadata: metadata,
 		Logging:  logging,
 		Sources:  sources,
+	}, diags
+}
+
+// serviceKeys identifies the entries of a service a startrail_service resource
+// manages: access entries by endpoint, logging configurations and sources by
+// source, and metadata labels by key.
+type serviceKeys struct {
+	access  map[string]bool
+	logging map[string]bool
+	sources map[string]bool
+	labels  map[string]bool
+}
+
+// managedKeys returns the keys of the entries declared in the given models.
+func managedKeys(models ...ServiceModel) serviceKeys {
+	keys := serviceKeys{
+		access:  map[string]bool{},
+		logging: map[string]bool{},
+		sources: map[string]bool{},
+		labels:  map[string]bool{},
+	}
+	for _, m := range models {
+		for _, a := range m.Access {
+			keys.access[a.Endpoint.ValueString()] = true
+		}
+		for _, l := range m.Logging {
+			keys.logging[l.Source.ValueString()] = true
+		}
+		for _, s := range m.Sources {
+			keys.sources[s.Source.ValueString()] = true
+		}
+		if m.Metadata != nil {
+			for k := range m.Metadata.Labels.Elements() {
+				keys.labels[k] = true
+			}
+		}
 	}
+	return keys
+}
 
-	clientReq := r.client.Client.ServiceAPI.Create(ctx)
-	clientReq = clientReq.Service(service)
-	startrailResponse, execute, err := clientReq.Execute()
+// keepUnmanaged copies the entries of current which are not managed by the
+// resource over to service. They were added by attachment resources such as
+// startrail_service_access, or outside of Terraform, and would otherwise be
+// removed as the service is replaced as a whole.
+func keepUnmanaged(service *bindings.Service, current bindings.Service, managed serviceKeys) {
+	for _, a := range current.Access {
+		if !managed.access[a.Endpoint] {
+			service.Access = append(service.Access, a)
+		}
+	}
+	for k, l := range current.Logging {
+		if !managed.logging[k] {
+			service.Logging[k] = l
+		}
+	}
+	for k, s := range current.Sources {
+		if !managed.sources[k] {
+			service.Sources[k] = s
+		}
+	}
+	if m := current.Metadata.Get(); m != nil {
+		for k, v := range m.Labels {
+			if !managed.labels[k] {
+				serviceLabels(service)[k] = v
+			}
+		}
+	}
+}
 
-	// error handling
-	if err != nil {
-		diags.AddError("Client Error", fmt.Sprintf("Unable to update service, got error: %s", err))
-		return ServiceModel{}, diags
+// onlyManaged drops the entries of data which are not managed by the resource.
+func onlyManaged(data *ServiceModel, managed serviceKeys) {
+	var access []ServiceResourceModelAccess
+	for _, a := range data.Access {
+		if managed.access[a.Endpoint.ValueString()] {
+			access = append(access, a)
+		}
 	}
-	handleStartrailDiagnostics(startrailResponse.GetDiagnostics(), &diags)
-	if diags.HasError() {
-		return ServiceModel{}, diags
+	data.Access = access
+
+	var logging []ServiceResourceModelLogging
+	for _, l := range data.Logging {
+		if managed.logging[l.Source.ValueString()] {
+			logging = append(logging, l)
+		}
 	}
-	if execute.StatusCode != 200 {
-		diags.AddError("Client Error", fmt.Sprintf("Unable to update service, got errors: %s", execute.Body))
-		return ServiceModel{}, diags
+	data.Logging = logging
+
+	var sources []ServiceResourceM0delSource
+	for _, s := range data.Sources {
+		if managed.sources[s.Source.ValueString()] {
+			sources = append(sources, s)
+		}
 	}
+	data.Sources = sources
 
-	data, diags = parseServiceResponse(startrailResponse)
-	return data, diags
+	if data.Metadata != nil && !data.Metadata.Labels.IsNull() {
+		labels := map[string]attr.Value{}
+		for k, v := range data.Metadata.Labels.Elements() {
+			if managed.labels[k] {
+				labels[k] = v
+			}
+		}
+		data.Metadata.Labels = types.MapValueMust(types.StringType, labels)
+	}
 }
 
-func parseServiceResponse(startrailResponse *bindings.ServiceResponse) (data ServiceModel, diags diag.Diagnostics) {
-	s := startrailResponse.GetResponse()
+// ignoreLabels drops the labels managed outside of Terraform from data, and
+// carries ignore_label_prefixes over from prior, the plan or the prior state.
+func (r *ServiceResource) ignoreLabels(ctx context.Context, prior ServiceModel, data *ServiceModel) diag.Diagnostics {
+	data.IgnoreLabelPrefixes = prior.IgnoreLabelPrefixes
+	prefixes, diags := r.client.labelPrefixes(ctx, prior.IgnoreLabelPrefixes)
+	if data.Metadata != nil {
+		data.Metadata.Labels = withoutIgnoredLabelValues(data.Metadata.Labels, prefixes)
+	}
+	return diags
+}
+
+// keepEmptyLabels carries null and empty label maps over from prior, the plan
+// or the prior state, wherever the backend returned no labels. The backend
+// returns {} for labels which are not set, which would otherwise show up as a
+// perpetual diff against configurations leaving them out.
+func keepEmptyLabels(prior ServiceModel, data *ServiceModel) {
+	if data.Metadata != nil && len(data.Metadata.Labels.Elements()) == 0 {
+		if prior.Metadata == nil {
+			data.Metadata = nil
+		} else {
+			data.Metadata.Labels = emptyLabels(prior.Metadata.Labels)
+		}
+	}
+
+	logging := map[string]types.Map{}
+	for _, l := range prior.Logging {
+		logging[l.Source.ValueString()] = l.Labels
+	}
+	for i, l := range data.Logging {
+		if len(l.Labels.Elements()) == 0 {
+			data.Logging[i].Labels = emptyLabels(logging[l.Source.ValueString()])
+		}
+	}
 
+	sources := map[string]types.Map{}
+	for _, s := range prior.Sources {
+		sources[s.Source.ValueString()] = s.Labels
+	}
+	for i, s := range data.Sources {
+		if len(s.Labels.Elements()) == 0 {
+			data.Sources[i].Labels = emptyLabels(sources[s.Source.ValueString()])
+		}
+	}
+}
+
+// emptyLabels returns prior if it is an empty label map, and a null map
+// otherwise.
+func emptyLabels(prior types.Map) types.Map {
+	if !prior.IsNull() && !prior.IsUnknown() && len(prior.Elements()) == 0 {
+		return prior
+	}
+	return types.MapNull(types.StringType)
+}
+
+func parseService(s bindings.Service) (data ServiceModel, diags diag.Diagnostics) {
+
+	// maps are returned in random order, sort them by source so that the
+	// blocks read back match the order they are written in configuration
 	var tfLogging []ServiceResourceModelLogging
-	for k, v := range s.Logging {
+	for _, k := range sortedKeys(s.Logging) {
+		v := s.Logging[k]
 		l := map[string]attr.Value{}
 		for k1, v1 := range v.Labels {
 			l[k1] = types.StringValue(v1)
@@ -368,7 +783,8 @@ func parseServiceResponse(startrailResponse *bindings.ServiceResponse) (data Ser
 		})
 	}
 	var tfSources []ServiceResourceM0delSource
-	for k, v := range s.Sources {
+	for _, k := range sortedKeys(s.Sources) {
+		v := s.Sources[k]
 		l := map[string]attr.Value{}
 		for k1, v1 := range v.Labels {
 			l[k1] = types.StringValue(v1)
@@ -425,6 +841,14 @@ func parseServiceResponse(startrailResponse *bindings.ServiceResponse) (data Ser
 }
 
 func (r *ServiceResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
+		return
+	}
+
+	ctx, cancel := r.client.writeContext(ctx)
+	defer cancel()
+
 	var data ServiceModel
 
 	// Read Terraform prior state data into the model
@@ -439,23 +863,50 @@ func (r *ServiceResource) Delete(ctx context.Context, req resource.DeleteRequest
 		environment = r.client.Environment
 	}
 
-	clientReq := r.client.Client.ServiceAPI.Delete(ctx, r.client.Tenant, environment, data.Name.ValueString())
-	startrailResponse, execute, err := clientReq.Execute()
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete service, got error: %s", err))
+	defer r.client.serviceCache.invalidate()
+
+	startrailResponse, execute, err := retryTransient(ctx, func(ctx context.Context) (*bindings.StringResponse, *http.Response, error) {
+		return r.client.Client.ServiceAPI.Delete(ctx, r.client.Tenant, normalizeEnvironment(environment), data.Name.ValueString()).Execute()
+	})
+	if !handleStartrailResponse(&resp.Diagnostics, "delete service", startrailResponse.GetDiagnostics(), execute, err) {
 		return
 	}
-	handleStartrailDiagnostics(startrailResponse.GetDiagnostics(), &resp.Diagnostics)
-	if resp.Diagnostics.HasError() {
+}
+
+func (r *ServiceResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if r.client == nil {
+		addProviderNotConfiguredError(&resp.Diagnostics)
 		return
 	}
 
-	if execute.StatusCode != 200 {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete service, got error: %s", execute.Body))
+	// the tenant and the environment may be omitted, in which case they are
+	// taken from the provider configuration
+	tenant := r.client.Tenant
+	environment := r.client.Environment
+	var name string
+
+	parts := strings.Split(req.ID, "/")
+	switch len(parts) {
+	case 1:
+		name = parts[0]
+	case 2:
+		environment, name = parts[0], parts[1]
+	case 3:
+		tenant, environment, name = parts[0], parts[1], parts[2]
+	}
+	if tenant == "" || name == "" || environment == "" {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: name, environment/name or tenant/environment/name. "+
+				"The environment can only be omitted when it is set in the provider configuration. Got: %q", req.ID),
+		)
+		return
+	}
+	if !r.client.checkImportTenant(&resp.Diagnostics, tenant) {
 		return
 	}
-}
 
-func (r *ServiceResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), fmt.Sprintf("%s/%s/%s", tenant, environment, name))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("environment"), environment)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), name)...)
 }
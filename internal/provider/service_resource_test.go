@@ -0,0 +1,461 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+	"github.com/srevinsaju/terraform-provider-startrail/internal/acctest"
+)
+
+func testServiceConfig(typ tftypes.Object, description string) tftypes.Value {
+	accessType := typ.AttributeTypes["access"].(tftypes.List)
+	metadataType := typ.AttributeTypes["metadata"].(tftypes.Object)
+	labelsType := metadataType.AttributeTypes["labels"]
+
+	return objectValue(typ, map[string]tftypes.Value{
+		"name":        stringValue("hello-world"),
+		"environment": stringValue("development"),
+		"description": stringValue(description),
+		"access": tftypes.NewValue(accessType, []tftypes.Value{
+			objectValue(accessType.ElementType.(tftypes.Object), map[string]tftypes.Value{
+				"auth":     tftypes.NewValue(tftypes.Bool, true),
+				"endpoint": stringValue("https://example.com/hello"),
+				"internal": tftypes.NewValue(tftypes.Bool, false),
+			}),
+		}),
+		"metadata": objectValue(metadataType, map[string]tftypes.Value{
+			"labels": tftypes.NewValue(labelsType, map[string]tftypes.Value{
+				"team": stringValue("platform"),
+			}),
+		}),
+	})
+}
+
+func TestServiceResource(t *testing.T) {
+	p, mock := newTestProvider(t)
+	typ := p.resourceType("startrail_service")
+
+	// create
+	state := p.apply("startrail_service", tftypes.NewValue(typ, nil), testServiceConfig(typ, "A service which tells hello world"))
+	if got := stringAttribute(t, state, "id"); got != "default/development/hello-world" {
+		t.Errorf("expected id default/development/hello-world, got %s", got)
+	}
+	service, ok := mock.Service("default", "development", "hello-world")
+	if !ok {
+		t.Fatal("expected service to be created")
+	}
+	if len(service.Access) != 1 || service.Access[0].Endpoint != "https://example.com/hello" || !service.Access[0].Auth {
+		t.Errorf("unexpected access entries: %+v", service.Access)
+	}
+	if m := service.Metadata.Get(); m == nil || m.Labels["team"] != "platform" {
+		t.Errorf("expected metadata label team=platform, got %+v", m)
+	}
+
+	// read
+	state = p.read("startrail_service", state)
+	if got := stringAttribute(t, state, "description"); got != "A service which tells hello world" {
+		t.Errorf("unexpected description after read: %s", got)
+	}
+
+	// update
+	state = p.apply("startrail_service", state, testServiceConfig(typ, "A service which tells hello"))
+	if got := stringAttribute(t, state, "description"); got != "A service which tells hello" {
+		t.Errorf("unexpected description after update: %s", got)
+	}
+	if service, _ := mock.Service("default", "development", "hello-world"); service.Description != "A service which tells hello" {
+		t.Errorf("expected description to be updated, got %s", service.Description)
+	}
+
+	// delete
+	p.apply("startrail_service", state, tftypes.NewValue(typ, nil))
+	if _, ok := mock.Service("default", "development", "hello-world"); ok {
+		t.Error("expected service to be deleted")
+	}
+}
+
+func TestServiceResource_import(t *testing.T) {
+	p, _ := newTestProvider(t)
+	typ := p.resourceType("startrail_service")
+
+	p.apply("startrail_service", tftypes.NewValue(typ, nil), testServiceConfig(typ, "A service which tells hello world"))
+
+	for _, id := range []string{"default/development/hello-world", "development/hello-world"} {
+		state := p.importState("startrail_service", id)
+		if got := stringAttribute(t, state, "id"); got != "default/development/hello-world" {
+			t.Errorf("%s: expected id default/development/hello-world, got %s", id, got)
+		}
+		if got := stringAttribute(t, state, "name"); got != "hello-world" {
+			t.Errorf("%s: expected name hello-world, got %s", id, got)
+		}
+		if got := stringAttribute(t, state, "description"); got != "A service which tells hello world" {
+			t.Errorf("%s: unexpected description: %s", id, got)
+		}
+		var access []tftypes.Value
+		if err := attribute(t, state, "access").As(&access); err != nil || len(access) != 1 {
+			t.Errorf("%s: expected 1 access block, got %d (%v)", id, len(access), err)
+		}
+	}
+	if got := p.importStateError("startrail_service", "other/development/hello-world"); got != "Unexpected Import Tenant" {
+		t.Errorf("expected the import of another tenant to fail, got %q", got)
+	}
+}
+
+func TestServiceResource_environmentCase(t *testing.T) {
+	p, mock := newTestProvider(t)
+	typ := p.resourceType("startrail_service")
+
+	config := objectValue(typ, map[string]tftypes.Value{
+		"name":        stringValue("hello-world"),
+		"environment": stringValue("Development"),
+	})
+
+	state := p.apply("startrail_service", tftypes.NewValue(typ, nil), config)
+	if _, ok := mock.Service("default", "development", "hello-world"); !ok {
+		t.Fatal("expected service to be created in the lowercase environment")
+	}
+	state = p.read("startrail_service", state)
+	if got := stringAttribute(t, state, "environment"); got != "Development" {
+		t.Errorf("expected environment to keep the configured case, got %s", got)
+	}
+}
+
+func TestServiceResource_providerEnvironment(t *testing.T) {
+	mock := acctest.NewServer()
+	t.Cleanup(mock.Close)
+	p := configureTestProvider(t, mock.URL, "acctest", nil, map[string]tftypes.Value{
+		"environment": stringValue("development"),
+	})
+	typ := p.resourceType("startrail_service")
+
+	config := func(description string) tftypes.Value {
+		return objectValue(typ, map[string]tftypes.Value{
+			"name":        stringValue("hello-world"),
+			"description": stringValue(description),
+		})
+	}
+
+	state := p.apply("startrail_service", tftypes.NewValue(typ, nil), config("A service which tells hello world"))
+	if got := stringAttribute(t, state, "environment"); got != "development" {
+		t.Errorf("expected the environment of the provider, got %s", got)
+	}
+	if _, ok := mock.Service("default", "development", "hello-world"); !ok {
+		t.Fatal("expected service to be created in the environment of the provider")
+	}
+
+	// updates keep the service in place
+	state = p.apply("startrail_service", p.read("startrail_service", state), config("A service which tells hello"))
+	if service, _ := mock.Service("default", "development", "hello-world"); service.Description != "A service which tells hello" {
+		t.Errorf("expected description to be updated, got %s", service.Description)
+	}
+}
+
+func TestServiceResource_environmentNotConfigured(t *testing.T) {
+	p, _ := newTestProvider(t)
+	typ := p.resourceType("startrail_service")
+
+	config := objectValue(typ, map[string]tftypes.Value{
+		"name": stringValue("hello-world"),
+	})
+	if got := p.planError("startrail_service", tftypes.NewValue(typ, nil), config); got != "Environment Not Configured" {
+		t.Errorf("expected the environment to be reported as not configured, got %q", got)
+	}
+}
+
+func TestServiceResource_drift(t *testing.T) {
+	p, mock := newTestProvider(t)
+	typ := p.resourceType("startrail_service")
+	accessType := typ.AttributeTypes["access"].(tftypes.List)
+	loggingType := typ.AttributeTypes["logging"].(tftypes.List)
+	metadataType := typ.AttributeTypes["metadata"].(tftypes.Object)
+
+	access := tftypes.NewValue(accessType, []tftypes.Value{
+		objectValue(accessType.ElementType.(tftypes.Object), map[string]tftypes.Value{
+			"endpoint": stringValue("https://example.com/hello"),
+		}),
+	})
+	config := objectValue(typ, map[string]tftypes.Value{
+		"name":        stringValue("hello-world"),
+		"environment": stringValue("development"),
+		"access":      access,
+		"logging": tftypes.NewValue(loggingType, []tftypes.Value{
+			objectValue(loggingType.ElementType.(tftypes.Object), map[string]tftypes.Value{
+				"source": stringValue("stdout"),
+			}),
+		}),
+		"metadata": objectValue(metadataType, nil),
+	})
+	state := p.apply("startrail_service", tftypes.NewValue(typ, nil), config)
+
+	// unset flags in state are read back as false
+	var vals map[string]tftypes.Value
+	_ = state.As(&vals)
+	prior := map[string]tftypes.Value{}
+	for k, v := range vals {
+		prior[k] = v
+	}
+	prior["access"] = access
+	state = tftypes.NewValue(typ, prior)
+
+	state, warnings := p.readWarnings("startrail_service", state)
+	if len(warnings) != 0 {
+		t.Errorf("expected no warnings of a refresh without changes, got %v", warnings)
+	}
+
+	service, _ := mock.Service("default", "development", "hello-world")
+	service.Description = "Changed outside of Terraform"
+	mock.PutService(service)
+	if _, warnings = p.readWarnings("startrail_service", state); len(warnings) != 1 || warnings[0] != "Service Changed Outside of Terraform" {
+		t.Errorf("expected a warning about the changed description, got %v", warnings)
+	}
+}
+
+func TestServiceResource_emptyLabels(t *testing.T) {
+	p, _ := newTestProvider(t)
+	typ := p.resourceType("startrail_service")
+	loggingType := typ.AttributeTypes["logging"].(tftypes.List)
+
+	config := objectValue(typ, map[string]tftypes.Value{
+		"name":        stringValue("hello-world"),
+		"environment": stringValue("development"),
+		"logging": tftypes.NewValue(loggingType, []tftypes.Value{
+			objectValue(loggingType.ElementType.(tftypes.Object), map[string]tftypes.Value{
+				"source": stringValue("stdout"),
+			}),
+		}),
+	})
+
+	check := func(operation string, state tftypes.Value) {
+		t.Helper()
+
+		var logging []tftypes.Value
+		if err := attribute(t, state, "logging").As(&logging); err != nil || len(logging) != 1 {
+			t.Fatalf("%s: expected 1 logging block, got %d (%v)", operation, len(logging), err)
+		}
+		if labels := attribute(t, logging[0], "labels"); !labels.IsNull() {
+			t.Errorf("%s: expected null logging labels, got %s", operation, labels)
+		}
+		if metadata := attribute(t, state, "metadata"); !metadata.IsNull() {
+			t.Errorf("%s: expected null metadata, got %s", operation, metadata)
+		}
+	}
+
+	// the backend returns {} for the labels left out of config
+	state := p.apply("startrail_service", tftypes.NewValue(typ, nil), config)
+	check("create", state)
+	check("read", p.read("startrail_service", state))
+}
+
+func TestServiceResource_ignoreLabelPrefixes(t *testing.T) {
+	p, mock := newTestProvider(t)
+	typ := p.resourceType("startrail_service")
+
+	config := func(description string) tftypes.Value {
+		var vals map[string]tftypes.Value
+		_ = testServiceConfig(typ, description).As(&vals)
+		vals["ignore_label_prefixes"] = tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{
+			stringValue("startrail.io/"),
+		})
+		return tftypes.NewValue(typ, vals)
+	}
+
+	state := p.apply("startrail_service", tftypes.NewValue(typ, nil), config("A service which tells hello world"))
+
+	// the backend injects a system label
+	service, _ := mock.Service("default", "development", "hello-world")
+	service.Metadata.Get().Labels["startrail.io/managed-by"] = "startrail"
+	mock.PutService(service)
+
+	state = p.read("startrail_service", state)
+	var metadata map[string]tftypes.Value
+	var labels map[string]tftypes.Value
+	if err := attribute(t, state, "metadata").As(&metadata); err != nil {
+		t.Fatalf("unable to convert metadata: %s", err)
+	}
+	if err := metadata["labels"].As(&labels); err != nil {
+		t.Fatalf("unable to convert labels: %s", err)
+	}
+	if _, ok := labels["startrail.io/managed-by"]; ok || len(labels) != 1 {
+		t.Errorf("expected only the team label in state, got %v", labels)
+	}
+
+	p.apply("startrail_service", state, config("A service which tells hello"))
+	service, _ = mock.Service("default", "development", "hello-world")
+	if got := service.Metadata.Get().Labels["startrail.io/managed-by"]; got != "startrail" {
+		t.Errorf("expected the system label to be kept on the service, got %q", got)
+	}
+}
+
+func TestServiceResource_writeErrors(t *testing.T) {
+	mock := acctest.NewServer()
+	t.Cleanup(mock.Close)
+
+	rejected := false
+	p := configureTestProvider(t, mock.URL, "acctest", &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodPost && rejected {
+			return &http.Response{StatusCode: http.StatusBadRequest, Status: "400 Bad Request", Body: http.NoBody, Request: req}, nil
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})}, nil)
+	typ := p.resourceType("startrail_service")
+
+	// failures name the operation which failed
+	rejected = true
+	d := p.applyError("startrail_service", tftypes.NewValue(typ, nil), testServiceConfig(typ, "A service which tells hello world"))
+	if !strings.HasPrefix(d.Detail, "Unable to create service") {
+		t.Errorf("expected the create to fail, got: %s", d.Detail)
+	}
+
+	rejected = false
+	state := p.apply("startrail_service", tftypes.NewValue(typ, nil), testServiceConfig(typ, "A service which tells hello world"))
+	rejected = true
+	d = p.applyError("startrail_service", state, testServiceConfig(typ, "A service which tells hello"))
+	if !strings.HasPrefix(d.Detail, "Unable to update service") {
+		t.Errorf("expected the update to fail, got: %s", d.Detail)
+	}
+}
+
+func TestServiceResource_planUnconfiguredAttributes(t *testing.T) {
+	p, mock := newTestProvider(t)
+	typ := p.resourceType("startrail_service")
+
+	// description and remarks are set outside of Terraform
+	mock.PutService(bindings.Service{
+		Tenant:      "default",
+		Environment: "development",
+		Name:        "hello-world",
+		Description: "Set outside of Terraform",
+		Remarks:     "Reviewed",
+		Access:      []bindings.Access{{Endpoint: "https://example.com/hello", Auth: true}},
+		Metadata:    *bindings.NewNullableMetadata(bindings.NewMetadata(map[string]string{"team": "platform"})),
+	})
+	state := p.read("startrail_service", p.importState("startrail_service", "development/hello-world"))
+
+	config := func(endpoint string) tftypes.Value {
+		var vals map[string]tftypes.Value
+		_ = testServiceConfig(typ, "").As(&vals)
+		vals["description"] = tftypes.NewValue(tftypes.String, nil)
+		vals["remarks"] = tftypes.NewValue(tftypes.String, nil)
+		var access []tftypes.Value
+		_ = vals["access"].As(&access)
+		var entry map[string]tftypes.Value
+		_ = access[0].As(&entry)
+		entry["endpoint"] = stringValue(endpoint)
+		vals["access"] = tftypes.NewValue(typ.AttributeTypes["access"], []tftypes.Value{
+			tftypes.NewValue(typ.AttributeTypes["access"].(tftypes.List).ElementType, entry),
+		})
+		return tftypes.NewValue(typ, vals)
+	}
+
+	// an unrelated change does not show them as known after apply
+	for _, endpoint := range []string{"https://example.com/hello", "https://example.com/world"} {
+		plan := p.plan("startrail_service", state, config(endpoint))
+		for name, want := range map[string]string{"description": "Set outside of Terraform", "remarks": "Reviewed"} {
+			if v := attribute(t, plan, name); !v.IsKnown() {
+				t.Errorf("%s: expected %s to be known in the plan", endpoint, name)
+			} else if got := stringAttribute(t, plan, name); got != want {
+				t.Errorf("%s: expected %s %q in the plan, got %q", endpoint, name, want, got)
+			}
+		}
+	}
+}
+
+func TestServiceResource_bulkRead(t *testing.T) {
+	p, mock := newTestProvider(t)
+
+	for i := 0; i < 2*bulkReadThreshold; i++ {
+		mock.PutService(bindings.Service{
+			Tenant:      "default",
+			Environment: "development",
+			Name:        fmt.Sprintf("service-%d", i),
+			Description: fmt.Sprintf("Service %d", i),
+			Access:      []bindings.Access{},
+		})
+	}
+
+	// reads past the threshold are served from the list of services
+	for i := 0; i < 2*bulkReadThreshold; i++ {
+		state := p.importState("startrail_service", fmt.Sprintf("development/service-%d", i))
+		if got := stringAttribute(t, state, "description"); got != fmt.Sprintf("Service %d", i) {
+			t.Errorf("service-%d: unexpected description: %s", i, got)
+		}
+	}
+
+	if got := mock.Requests(http.MethodGet, "/api/v1/service/default"); got != 1 {
+		t.Errorf("expected services to be listed once, got %d list requests", got)
+	}
+	for i := 0; i < 2*bulkReadThreshold; i++ {
+		expected := 1
+		if i >= bulkReadThreshold {
+			expected = 0
+		}
+		if got := mock.Requests(http.MethodGet, fmt.Sprintf("/api/v1/service/default/development/service-%d", i)); got != expected {
+			t.Errorf("service-%d: expected %d get requests, got %d", i, expected, got)
+		}
+	}
+}
+
+func TestServiceResource_bulkReadFailed(t *testing.T) {
+	mock := acctest.NewServer()
+	t.Cleanup(mock.Close)
+
+	// the tenant may not list its services
+	lists := 0
+	p := configureTestProvider(t, mock.URL, "acctest", &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodGet && req.URL.Path == "/api/v1/service/default" {
+			lists++
+			return &http.Response{StatusCode: http.StatusForbidden, Body: http.NoBody, Request: req}, nil
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})}, nil)
+
+	for i := 0; i < 3*bulkReadThreshold; i++ {
+		mock.PutService(bindings.Service{
+			Tenant:      "default",
+			Environment: "development",
+			Name:        fmt.Sprintf("service-%d", i),
+			Description: fmt.Sprintf("Service %d", i),
+			Access:      []bindings.Access{},
+		})
+	}
+
+	// a failed list call falls back to single reads, without listing again
+	for i := 0; i < 3*bulkReadThreshold; i++ {
+		state := p.importState("startrail_service", fmt.Sprintf("development/service-%d", i))
+		if got := stringAttribute(t, state, "description"); got != fmt.Sprintf("Service %d", i) {
+			t.Errorf("service-%d: unexpected description: %s", i, got)
+		}
+	}
+	if lists != 1 {
+		t.Errorf("expected a single list request, got %d", lists)
+	}
+	for i := 0; i < 3*bulkReadThreshold; i++ {
+		if got := mock.Requests(http.MethodGet, fmt.Sprintf("/api/v1/service/default/development/service-%d", i)); got != 1 {
+			t.Errorf("service-%d: expected 1 get request, got %d", i, got)
+		}
+	}
+}
+
+func TestServiceResource_recorded(t *testing.T) {
+	p := newRecordedTestProvider(t)
+	typ := p.resourceType("startrail_service")
+
+	state := p.apply("startrail_service", tftypes.NewValue(typ, nil), testServiceConfig(typ, "A service which tells hello world"))
+	state = p.read("startrail_service", state)
+	if got := stringAttribute(t, state, "description"); got != "A service which tells hello world" {
+		t.Errorf("unexpected description after read: %s", got)
+	}
+
+	state = p.apply("startrail_service", state, testServiceConfig(typ, "A service which tells hello"))
+	if got := stringAttribute(t, state, "description"); got != "A service which tells hello" {
+		t.Errorf("unexpected description after update: %s", got)
+	}
+
+	p.apply("startrail_service", state, tftypes.NewValue(typ, nil))
+}
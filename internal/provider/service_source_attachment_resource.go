@@ -0,0 +1,49 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &ServiceSourceAttachmentResource{}
+var _ resource.ResourceWithImportState = &ServiceSourceAttachmentResource{}
+
+func NewServiceSourceAttachmentResource() resource.Resource {
+	return &ServiceSourceAttachmentResource{
+		serviceAttachment: serviceAttachment[bindings.Source]{
+			kind: "source",
+			entries: func(service *bindings.Service) *map[string]bindings.Source {
+				return &service.Sources
+			},
+			newEntry: func(labels map[string]string) bindings.Source {
+				return bindings.Source{Labels: labels}
+			},
+			entryLabels: func(entry bindings.Source) map[string]string {
+				return entry.Labels
+			},
+		},
+	}
+}
+
+// ServiceSourceAttachmentResource attaches a registered source to an
+// existing service.
+type ServiceSourceAttachmentResource struct {
+	serviceAttachment[bindings.Source]
+}
+
+func (r *ServiceSourceAttachmentResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_service_source_attachment"
+}
+
+func (r *ServiceSourceAttachmentResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = serviceAttachmentSchema(
+		"Attaches a registered source to an existing service. "+
+			"The service may be managed by a `startrail_service` resource, as long as the source is not declared in one of its `source` blocks as well.",
+		"source",
+	)
+}
@@ -0,0 +1,26 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	bindings "github.com/srevinsaju/startrail-go-sdk"
+)
+
+func serviceSources(service bindings.Service) map[string]map[string]string {
+	entries := map[string]map[string]string{}
+	for k, s := range service.Sources {
+		entries[k] = s.Labels
+	}
+	return entries
+}
+
+func TestServiceSourceAttachmentResource(t *testing.T) {
+	testServiceAttachment(t, "startrail_service_source_attachment", serviceSources)
+}
+
+func TestServiceSourceAttachmentResource_managedService(t *testing.T) {
+	testServiceAttachment_managedService(t, "startrail_service_source_attachment", "source", serviceSources)
+}
@@ -0,0 +1,240 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"regexp"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ServiceSpecDataSource{}
+
+func NewServiceSpecDataSource() datasource.DataSource {
+	return &ServiceSpecDataSource{}
+}
+
+// ServiceSpecDataSource renders the service described by the same blocks as
+// startrail_service to the JSON sent to the Startrail API, without sending it.
+type ServiceSpecDataSource struct {
+	client *StartrailProviderClient
+}
+
+// ServiceSpecDataSourceModel describes the data source data model.
+type ServiceSpecDataSourceModel struct {
+	Id          types.String                  `tfsdk:"id"`
+	Name        types.String                  `tfsdk:"name"`
+	Environment types.String                  `tfsdk:"environment"`
+	Description types.String                  `tfsdk:"description"`
+	Remarks     types.String                  `tfsdk:"remarks"`
+	Access      []ServiceResourceModelAccess  `tfsdk:"access"`
+	Logging     []ServiceResourceModelLogging `tfsdk:"logging"`
+	Metadata    *ServiceResourceModelMetadata `tfsdk:"metadata"`
+	Sources     []ServiceResourceM0delSource  `tfsdk:"source"`
+	Json        types.String                  `tfsdk:"json"`
+
+	IgnoreLabelPrefixes types.List `tfsdk:"ignore_label_prefixes"`
+}
+
+func (d *ServiceSpecDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_service_spec"
+}
+
+func (d *ServiceSpecDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	labels := schema.MapAttribute{
+		Description: "Labels to apply to the service",
+		Optional:    true,
+		ElementType: types.StringType,
+	}
+
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Renders a service, described by the same arguments as `startrail_service`, to the JSON " +
+			"the provider sends to the Startrail API, without creating anything.",
+
+		Blocks: map[string]schema.Block{
+			"access": schema.ListNestedBlock{
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"auth": schema.BoolAttribute{
+							Description: "Set to true if this endpoint requires authentication to connect",
+							Optional:    true,
+						},
+						"endpoint": schema.StringAttribute{
+							Description: "The upstream endpoint to use for API requests.",
+							Required:    true,
+						},
+						"internal": schema.BoolAttribute{
+							Description: "Set to true if this endpoint is internal to the cluster",
+							Optional:    true,
+						},
+					},
+				},
+			},
+			"logging": schema.ListNestedBlock{
+				MarkdownDescription: "Logging configuration for the service",
+
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"labels": labels,
+						"source": schema.StringAttribute{
+							Description: "The source to use for the service",
+							Required:    true,
+						},
+					},
+				},
+			},
+			"source": schema.ListNestedBlock{
+				MarkdownDescription: "List of sources to use for the service, this is a map of source names to source configurations.",
+
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"labels": labels,
+						"source": schema.StringAttribute{
+							Description: "The source to use for the service",
+							Required:    true,
+						},
+					},
+				},
+			},
+			"metadata": schema.SingleNestedBlock{
+				MarkdownDescription: "Metadata to apply to the service",
+
+				Attributes: map[string]schema.Attribute{
+					"labels": labels,
+				},
+			},
+		},
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Service identifier",
+				Computed:            true,
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Service name",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(
+						regexp.MustCompile(`^[a-z0-9-]+$`), "Name of the service must be lowercase alphanumeric with dashes"),
+				},
+			},
+			"environment": schema.StringAttribute{
+				MarkdownDescription: "Service environment, defaults to the environment of the provider configuration",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					environmentValidator{},
+					stringvalidator.RegexMatches(
+						regexp.MustCompile(`^[A-Za-z0-9-]+$`), "Environment must be alphanumeric with dashes"),
+				},
+			},
+			"ignore_label_prefixes": schema.ListAttribute{
+				MarkdownDescription: "Prefixes of metadata labels managed outside of Terraform, in addition to those of the provider configuration",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"description": schema.StringAttribute{
+				MarkdownDescription: "Service description",
+				Optional:            true,
+			},
+			"remarks": schema.StringAttribute{
+				MarkdownDescription: "Service remarks",
+				Optional:            true,
+			},
+			"json": schema.StringAttribute{
+				MarkdownDescription: "The service as JSON, with keys in sorted order",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *ServiceSpecDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*StartrailProviderClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *StartrailProviderClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *ServiceSpecDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ServiceSpecDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// nothing is sent, so the spec can be rendered without credentials
+	client := d.client
+	if client == nil {
+		client = &StartrailProviderClient{Tenant: "default"}
+	}
+	tenant := client.Tenant
+
+	environment := data.Environment.ValueString()
+	if environment == "" {
+		environment = client.Environment
+		data.Environment = types.StringValue(environment)
+	}
+	if environment == "" {
+		addEnvironmentNotConfiguredError(&resp.Diagnostics, path.Root("environment"))
+		return
+	}
+
+	prefixes, diags := client.labelPrefixes(ctx, data.IgnoreLabelPrefixes)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	service, diags := buildService(ctx, ServiceModel{
+		Name:        data.Name,
+		Description: data.Description,
+		Remarks:     data.Remarks,
+		Environment: data.Environment,
+		Disabled:    types.BoolNull(),
+		Access:      data.Access,
+		Logging:     data.Logging,
+		Metadata:    data.Metadata,
+		Sources:     data.Sources,
+	}, tenant, environment, prefixes)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	b, err := json.Marshal(service)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", "Unable to render service, got error: "+err.Error())
+		return
+	}
+
+	data.Id = types.StringValue(fmt.Sprintf("%s/%s/%s", tenant, service.Environment, service.Name))
+	data.Json = types.StringValue(string(b))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
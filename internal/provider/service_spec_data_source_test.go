@@ -0,0 +1,121 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/srevinsaju/terraform-provider-startrail/internal/acctest"
+)
+
+func TestServiceSpecDataSource(t *testing.T) {
+	p, mock := newTestProvider(t)
+	typ := p.dataSourceType("startrail_service_spec")
+	accessType := typ.AttributeTypes["access"].(tftypes.List)
+	metadataType := typ.AttributeTypes["metadata"].(tftypes.Object)
+
+	state := p.readDataSource("startrail_service_spec", objectValue(typ, map[string]tftypes.Value{
+		"name":        stringValue("hello-world"),
+		"environment": stringValue("Development"),
+		"description": stringValue("A service which tells hello world"),
+		"access": tftypes.NewValue(accessType, []tftypes.Value{
+			objectValue(accessType.ElementType.(tftypes.Object), map[string]tftypes.Value{
+				"endpoint": stringValue("https://example.com/hello"),
+				"auth":     tftypes.NewValue(tftypes.Bool, true),
+			}),
+		}),
+		"metadata": objectValue(metadataType, map[string]tftypes.Value{
+			"labels": tftypes.NewValue(metadataType.AttributeTypes["labels"], map[string]tftypes.Value{
+				"team": stringValue("platform"),
+			}),
+		}),
+	}))
+
+	want := `{"access":[{"auth":true,"endpoint":"https://example.com/hello","internal":false}],` +
+		`"description":"A service which tells hello world","environment":"development",` +
+		`"logging":{},"metadata":{"labels":{"team":"platform"}},"name":"hello-world",` +
+		`"remarks":"","sources":{},"tenant":"default"}`
+	if got := stringAttribute(t, state, "json"); got != want {
+		t.Errorf("unexpected json:\n got: %s\nwant: %s", got, want)
+	}
+	if got := stringAttribute(t, state, "id"); got != "default/development/hello-world" {
+		t.Errorf("unexpected id: %s", got)
+	}
+	if _, ok := mock.Service("default", "development", "hello-world"); ok {
+		t.Error("expected no service to be created")
+	}
+}
+
+func TestServiceSpecDataSource_providerEnvironment(t *testing.T) {
+	mock := acctest.NewServer()
+	t.Cleanup(mock.Close)
+	p := configureTestProvider(t, mock.URL, "acctest", nil, map[string]tftypes.Value{
+		"environment": stringValue("development"),
+		"ignore_label_prefixes": tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{
+			stringValue("startrail.io/"),
+		}),
+	})
+	typ := p.dataSourceType("startrail_service_spec")
+	metadataType := typ.AttributeTypes["metadata"].(tftypes.Object)
+	metadata := func(key string) tftypes.Value {
+		return objectValue(metadataType, map[string]tftypes.Value{
+			"labels": tftypes.NewValue(metadataType.AttributeTypes["labels"], map[string]tftypes.Value{
+				key: stringValue("platform"),
+			}),
+		})
+	}
+
+	// the body of a startrail_service relying on the provider configuration
+	state := p.readDataSource("startrail_service_spec", objectValue(typ, map[string]tftypes.Value{
+		"name":     stringValue("hello-world"),
+		"metadata": metadata("team"),
+	}))
+	if got := stringAttribute(t, state, "environment"); got != "development" {
+		t.Errorf("expected the environment of the provider, got %s", got)
+	}
+	if got := stringAttribute(t, state, "id"); got != "default/development/hello-world" {
+		t.Errorf("unexpected id: %s", got)
+	}
+
+	// labels are checked against the prefixes of the provider and the spec
+	for _, tc := range []struct {
+		key      string
+		prefixes []tftypes.Value
+	}{
+		{key: "startrail.io/managed-by"},
+		{key: "example.com/owner", prefixes: []tftypes.Value{stringValue("example.com/")}},
+	} {
+		config := objectValue(typ, map[string]tftypes.Value{
+			"name":                  stringValue("hello-world"),
+			"metadata":              metadata(tc.key),
+			"ignore_label_prefixes": tftypes.NewValue(typ.AttributeTypes["ignore_label_prefixes"], tc.prefixes),
+		})
+		if got := p.readDataSourceError("startrail_service_spec", config); got != "Ignored Label Configured" {
+			t.Errorf("%s: unexpected error: %s", tc.key, got)
+		}
+	}
+
+	// names are validated like those of startrail_service
+	if got := p.readDataSourceError("startrail_service_spec", objectValue(typ, map[string]tftypes.Value{
+		"name": stringValue("Hello World"),
+	})); got != "Invalid Attribute Value Match" {
+		t.Errorf("unexpected error for an invalid name: %s", got)
+	}
+}
+
+func TestServiceSpecDataSource_environmentNotConfigured(t *testing.T) {
+	p, _ := newTestProvider(t)
+	typ := p.dataSourceType("startrail_service_spec")
+
+	for _, environment := range []tftypes.Value{tftypes.NewValue(tftypes.String, nil), stringValue("")} {
+		config := objectValue(typ, map[string]tftypes.Value{
+			"name":        stringValue("hello-world"),
+			"environment": environment,
+		})
+		if got := p.readDataSourceError("startrail_service_spec", config); got != "Environment Not Configured" {
+			t.Errorf("environment %s: unexpected error: %s", environment, got)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+	"github.com/zalando/go-keyring"
+	"golang.org/x/oauth2"
+	"sync"
+)
+
+// tokenSources holds a token source per endpoint and OAuth client for the
+// lifetime of the process. Aliased provider blocks against the same endpoint
+// share it, so the refresh token is redeemed once instead of every block
+// rotating it and invalidating the tokens of the others.
+var tokenSources = struct {
+	mu      sync.Mutex
+	sources map[string]oauth2.TokenSource
+}{
+	sources: map[string]oauth2.TokenSource{},
+}
+
+// sharedTokenSource returns the token source for the endpoint and the client
+// of config, creating it from the refresh token in the keyring on first use.
+func sharedTokenSource(endpoint string, config oauth2.Config) (oauth2.TokenSource, error) {
+	key := endpoint + "\x00" + config.ClientID
+
+	tokenSources.mu.Lock()
+	defer tokenSources.mu.Unlock()
+
+	if ts, ok := tokenSources.sources[key]; ok {
+		return ts, nil
+	}
+
+	refreshToken, err := keyring.Get("startrail", "refresh_token")
+	if err != nil {
+		return nil, err
+	}
+	// the token source outlives the Configure call it is created in
+	ts := oauth2.ReuseTokenSource(nil, &keyringTokenSource{
+		base:         config.TokenSource(context.Background(), &oauth2.Token{RefreshToken: refreshToken}),
+		refreshToken: refreshToken,
+	})
+	tokenSources.sources[key] = ts
+	return ts, nil
+}
+
+// keyringTokenSource writes rotated refresh tokens back to the keyring, so
+// that the next Terraform run starts from a valid one.
+type keyringTokenSource struct {
+	base         oauth2.TokenSource
+	refreshToken string
+}
+
+func (s *keyringTokenSource) Token() (*oauth2.Token, error) {
+	t, err := s.base.Token()
+	if err != nil {
+		return nil, err
+	}
+	if t.RefreshToken != "" && t.RefreshToken != s.refreshToken {
+		s.refreshToken = t.RefreshToken
+		_ = keyring.Set("startrail", "refresh_token", t.RefreshToken)
+	}
+	return t, nil
+}
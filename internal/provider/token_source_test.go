@@ -0,0 +1,122 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/oauth2"
+)
+
+// useTestTokenSources replaces the keyring with an in-memory one holding the
+// refresh token, and clears the shared token sources.
+func useTestTokenSources(t *testing.T, refreshToken string) {
+	keyring.MockInit()
+	if err := keyring.Set("startrail", "refresh_token", refreshToken); err != nil {
+		t.Fatal(err)
+	}
+
+	tokenSources.mu.Lock()
+	sources := tokenSources.sources
+	tokenSources.sources = map[string]oauth2.TokenSource{}
+	tokenSources.mu.Unlock()
+
+	t.Cleanup(func() {
+		_ = keyring.Delete("startrail", "refresh_token")
+		tokenSources.mu.Lock()
+		tokenSources.sources = sources
+		tokenSources.mu.Unlock()
+	})
+}
+
+func TestSharedTokenSource(t *testing.T) {
+	useTestTokenSources(t, "refresh-1")
+
+	var redeemed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redeemed = append(redeemed, r.FormValue("refresh_token"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"access-1","token_type":"Bearer","expires_in":3600,"refresh_token":"refresh-2"}`))
+	}))
+	defer server.Close()
+	config := oauth2.Config{ClientID: "terraform", Endpoint: oauth2.Endpoint{TokenURL: server.URL}}
+
+	// aliased provider blocks against the same endpoint share the source
+	first, err := sharedTokenSource("https://startrail.example.com", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := sharedTokenSource("https://startrail.example.com", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Fatal("expected the token source to be reused for the same endpoint and client")
+	}
+
+	for _, ts := range []oauth2.TokenSource{first, second} {
+		token, err := ts.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.AccessToken != "access-1" {
+			t.Errorf("unexpected access token: %s", token.AccessToken)
+		}
+	}
+	if len(redeemed) != 1 || redeemed[0] != "refresh-1" {
+		t.Errorf("expected the refresh token to be redeemed once, got %v", redeemed)
+	}
+
+	// the rotated refresh token is kept for the next run
+	if got, err := keyring.Get("startrail", "refresh_token"); err != nil || got != "refresh-2" {
+		t.Errorf("expected the rotated refresh token in the keyring, got %q (%v)", got, err)
+	}
+}
+
+func TestSharedTokenSource_separateKeys(t *testing.T) {
+	useTestTokenSources(t, "refresh-1")
+
+	ts, err := sharedTokenSource("https://startrail.example.com", oauth2.Config{ClientID: "terraform"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		endpoint string
+		clientID string
+	}{
+		{"https://startrail.example.org", "terraform"},
+		{"https://startrail.example.com", "other"},
+		// the key must not be ambiguous when joining endpoint and client
+		{"https://startrail.example.comterraform", ""},
+	} {
+		other, err := sharedTokenSource(tc.endpoint, oauth2.Config{ClientID: tc.clientID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if other == ts {
+			t.Errorf("expected a separate token source for %s and client %q", tc.endpoint, tc.clientID)
+		}
+	}
+}
+
+func TestSharedTokenSource_noRefreshToken(t *testing.T) {
+	useTestTokenSources(t, "refresh-1")
+	if err := keyring.Delete("startrail", "refresh_token"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sharedTokenSource("https://startrail.example.com", oauth2.Config{ClientID: "terraform"}); err == nil {
+		t.Error("expected an error without a refresh token in the keyring")
+	}
+	// a later login is picked up
+	if err := keyring.Set("startrail", "refresh_token", "refresh-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sharedTokenSource("https://startrail.example.com", oauth2.Config{ClientID: "terraform"}); err != nil {
+		t.Errorf("unexpected error after logging in: %s", err)
+	}
+}
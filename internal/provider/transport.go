@@ -0,0 +1,33 @@
+package provider
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// sharedHTTPClient is used by every provider instance of the process, so that
+// refreshing many resources reuses pooled keep-alive connections instead of
+// dialing the endpoint for each request.
+var sharedHTTPClient = &http.Client{
+	Transport: newTransport(),
+}
+
+func newTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2: true,
+		// Terraform runs up to 10 operations in parallel by default, and
+		// users commonly raise -parallelism, keep enough idle connections
+		// around for all of them against the single Startrail endpoint.
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   64,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
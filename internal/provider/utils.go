@@ -1,10 +1,25 @@
 package provider
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	bindings "github.com/srevinsaju/startrail-go-sdk"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
 )
 
+// maxErrorBodyLength is the number of bytes of a response body included in
+// error diagnostics.
+const maxErrorBodyLength = 1024
+
+// requestIDHeaders are the response headers carrying the identifier Startrail
+// support needs to trace a request, in order of preference.
+var requestIDHeaders = []string{"X-Request-Id", "X-Correlation-Id", "X-Trace-Id"}
+
 func handleStartrailDiagnostics(diagnostics []bindings.Diagnostic, diags *diag.Diagnostics) {
 
 	if diagnostics != nil {
@@ -17,3 +32,98 @@ func handleStartrailDiagnostics(diagnostics []bindings.Diagnostic, diags *diag.D
 		}
 	}
 }
+
+// handleStartrailResponse reports the outcome of a call to the Startrail API,
+// described by operation (e.g. "read service"), and returns false when the
+// call failed. Diagnostics returned by Startrail are forwarded, and failures
+// include the HTTP status, the request ID and the beginning of the response
+// body so that they can be investigated.
+func handleStartrailResponse(diags *diag.Diagnostics, operation string, diagnostics []bindings.Diagnostic, httpResp *http.Response, err error) bool {
+	if errors.Is(err, errEndpointUnavailable) {
+		diags.AddError(
+			"Startrail Endpoint Unavailable",
+			fmt.Sprintf("Unable to %s: the last %d requests to the Startrail endpoint failed, "+
+				"further requests are rejected for %s to let it recover.", operation, circuitBreakerThreshold, circuitBreakerCooldown),
+		)
+		return false
+	}
+
+	var body []byte
+	if err != nil {
+		var apiErr *bindings.GenericOpenAPIError
+		if errors.As(err, &apiErr) {
+			body = apiErr.Body()
+			// error responses carry diagnostics too
+			var errResp bindings.ErrorResponse
+			if json.Unmarshal(body, &errResp) == nil {
+				diagnostics = errResp.Diagnostics
+			}
+		}
+	}
+
+	errorCount := diags.ErrorsCount()
+	handleStartrailDiagnostics(diagnostics, diags)
+	reported := diags.ErrorsCount() > errorCount
+	if err == nil && !reported && httpResp != nil && httpResp.StatusCode == http.StatusOK {
+		return true
+	}
+	if err == nil && reported {
+		return false
+	}
+
+	var detail strings.Builder
+	fmt.Fprintf(&detail, "Unable to %s", operation)
+	if err != nil {
+		fmt.Fprintf(&detail, ", got error: %s", err)
+	}
+	if httpResp != nil {
+		fmt.Fprintf(&detail, "\n\nHTTP status: %s", httpResp.Status)
+		if id := requestID(httpResp); id != "" {
+			fmt.Fprintf(&detail, "\nRequest ID: %s", id)
+		}
+		if body == nil && httpResp.Body != nil {
+			body, _ = io.ReadAll(io.LimitReader(httpResp.Body, maxErrorBodyLength+1))
+		}
+	}
+	if len(body) > 0 {
+		truncated := string(body)
+		if len(truncated) > maxErrorBodyLength {
+			truncated = truncated[:maxErrorBodyLength] + "... (truncated)"
+		}
+		fmt.Fprintf(&detail, "\nResponse body: %s", truncated)
+	}
+
+	diags.AddError("Client Error", detail.String())
+	return false
+}
+
+// requestID returns the request identifier Startrail attached to the response.
+func requestID(httpResp *http.Response) string {
+	for _, h := range requestIDHeaders {
+		if id := httpResp.Header.Get(h); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// addProviderNotConfiguredError reports that the provider client is not
+// available, which happens while the provider configuration depends on values
+// that are not known yet.
+func addProviderNotConfiguredError(diags *diag.Diagnostics) {
+	diags.AddError(
+		"Provider Not Configured",
+		"The provider configuration depends on values that are not known yet, such as credentials from an ephemeral resource or a resource which is not created yet. "+
+			"Make sure these values are available before the provider is used.",
+	)
+}
+
+// sortedKeys returns the keys of m in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
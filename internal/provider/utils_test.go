@@ -0,0 +1,138 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// readServiceFrom reads a service with the SDK from a server responding with
+// status, headers and body, and returns the diagnostics reported for it.
+func readServiceFrom(t *testing.T, status int, headers map[string]string, body string) diag.Diagnostics {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range headers {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newClient(u, "test", "apiKey acctest", false, nil, nil)
+
+	var diags diag.Diagnostics
+	resp, httpResp, err := client.ServiceAPI.Get(context.Background(), "default", "development", "hello-world").Execute()
+	if handleStartrailResponse(&diags, "read service", resp.GetDiagnostics(), httpResp, err) {
+		t.Fatalf("expected the response with status %d to fail", status)
+	}
+	return diags
+}
+
+func errorDetail(t *testing.T, diags diag.Diagnostics) string {
+	t.Helper()
+
+	if len(diags.Errors()) != 1 || diags.Errors()[0].Summary() != "Client Error" {
+		t.Fatalf("expected a single client error, got %v", diags)
+	}
+	return diags.Errors()[0].Detail()
+}
+
+func TestHandleStartrailResponse_requestID(t *testing.T) {
+	for _, tc := range []struct {
+		headers map[string]string
+		want    string
+	}{
+		{map[string]string{"X-Request-Id": "req-1"}, "req-1"},
+		{map[string]string{"X-Correlation-Id": "corr-1"}, "corr-1"},
+		{map[string]string{"X-Trace-Id": "trace-1"}, "trace-1"},
+		{map[string]string{"X-Trace-Id": "trace-1", "X-Request-Id": "req-1"}, "req-1"},
+		{map[string]string{}, ""},
+	} {
+		detail := errorDetail(t, readServiceFrom(t, http.StatusInternalServerError, tc.headers, "upstream failed"))
+
+		if !strings.Contains(detail, "HTTP status: 500 Internal Server Error") {
+			t.Errorf("expected the status in the detail, got: %s", detail)
+		}
+		if !strings.Contains(detail, "Response body: upstream failed") {
+			t.Errorf("expected the body in the detail, got: %s", detail)
+		}
+		switch {
+		case tc.want == "" && strings.Contains(detail, "Request ID"):
+			t.Errorf("expected no request ID for headers %v, got: %s", tc.headers, detail)
+		case tc.want != "" && !strings.Contains(detail, "\nRequest ID: "+tc.want+"\n"):
+			t.Errorf("expected request ID %s for headers %v, got: %s", tc.want, tc.headers, detail)
+		}
+	}
+}
+
+func TestHandleStartrailResponse_truncatedBody(t *testing.T) {
+	for _, tc := range []struct {
+		length    int
+		truncated bool
+	}{
+		{maxErrorBodyLength - 1, false},
+		{maxErrorBodyLength, false},
+		{maxErrorBodyLength + 1, true},
+		{4 * maxErrorBodyLength, true},
+	} {
+		detail := errorDetail(t, readServiceFrom(t, http.StatusBadRequest, nil, strings.Repeat("a", tc.length)))
+
+		want := tc.length
+		if tc.truncated {
+			want = maxErrorBodyLength
+		}
+		_, body, _ := strings.Cut(detail, "Response body: ")
+		body, truncated := strings.CutSuffix(body, "... (truncated)")
+		if truncated != tc.truncated {
+			t.Errorf("body of %d bytes: expected truncated to be %t, got: %s", tc.length, tc.truncated, body)
+		}
+		if body != strings.Repeat("a", want) {
+			t.Errorf("body of %d bytes: expected %d bytes in the detail, got %d", tc.length, want, len(body))
+		}
+	}
+}
+
+func TestHandleStartrailResponse_unreadResponse(t *testing.T) {
+	// a failed response which the SDK did not read the body of
+	rec := httptest.NewRecorder()
+	rec.Header().Set("X-Request-Id", "req-1")
+	rec.WriteHeader(http.StatusTeapot)
+	_, _ = rec.WriteString(strings.Repeat("a", 2*maxErrorBodyLength))
+
+	var diags diag.Diagnostics
+	if handleStartrailResponse(&diags, "read service", nil, rec.Result(), nil) {
+		t.Fatal("expected the response to fail")
+	}
+	detail := errorDetail(t, diags)
+	if !strings.Contains(detail, "\nRequest ID: req-1\n") {
+		t.Errorf("expected the request ID in the detail, got: %s", detail)
+	}
+	if !strings.HasSuffix(detail, strings.Repeat("a", maxErrorBodyLength)+"... (truncated)") {
+		t.Errorf("expected the truncated body in the detail, got: %s", detail)
+	}
+}
+
+func TestHandleStartrailResponse_diagnostics(t *testing.T) {
+	diags := readServiceFrom(t, http.StatusBadRequest, map[string]string{"Content-Type": "application/json"},
+		`{"success":false,"diagnostics":[{"severity":"Error","summary":"Invalid Service","detail":"The name is too long.","context":""}]}`)
+
+	if len(diags.Errors()) != 2 || diags.Errors()[0].Summary() != "Invalid Service" {
+		t.Fatalf("expected the diagnostics of the response and a client error, got %v", diags)
+	}
+	if detail := diags.Errors()[1].Detail(); !strings.Contains(detail, "HTTP status: 400 Bad Request") {
+		t.Errorf("expected the status in the detail, got: %s", detail)
+	}
+}